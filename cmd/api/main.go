@@ -1,21 +1,27 @@
 package main
 
 import (
+	"Berpg/internal/cache"
 	"Berpg/internal/handler"
 	"Berpg/internal/middleware"
+	"Berpg/internal/migration"
 	"Berpg/internal/repository"
 	"Berpg/internal/service"
-	"database/sql"
+	"context"
+	"errors"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	_ "github.com/mattn/go-sqlite3"
-	"github.com/redis/go-redis/v9"
 )
 
 func init() {
@@ -44,24 +50,96 @@ func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
-	//Database (SQLite WAL)
-	db, err := sql.Open("sqlite3", "file:app.db?cache=shared&_journal_mode=WAL&_busy_timeout=5000")
-	if err != nil {
-		panic(err)
-	}
-	db.SetMaxOpenConns(1) // Safe for SQLite Writer
+	// Storage: sqlite (default), postgres, atau memory
+	var userRepo repository.UserStore
+	var statsRepo repository.StatsStore
+	var seasonRepo repository.SeasonStore
+	var writeBehind *repository.WriteBehindRepository
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "memory" {
+		slog.Warn("DB_DRIVER=memory, data hilang saat restart")
+		memRepo := repository.NewMemoryUserRepository()
+		userRepo = memRepo
+		statsRepo = repository.NewMemoryStatsRepository()
+		seasonRepo = repository.NewMemorySeasonRepository(memRepo)
+	} else {
+		db, dialect, err := repository.OpenDB(driver, os.Getenv("DATABASE_URL"))
+		if err != nil {
+			panic(err)
+		}
+
+		// Jalankan migration yang belum ada (lihat juga cmd/migrate)
+		applied, err := migration.NewMigrator(db.Write, dialect).Up(context.Background())
+		if err != nil {
+			panic(err)
+		}
+		for _, m := range applied {
+			slog.Info("Migration dijalankan", "version", m.Version, "name", m.Name)
+		}
+		slog.Info("Database siap", "driver", dialect)
+
+		// Cache: redis (default, fallback ke memori kalau Redis mati), memory, atau none
+		cacheSize := 10000
+		if v, err := strconv.Atoi(os.Getenv("CACHE_SIZE")); err == nil && v > 0 {
+			cacheSize = v
+		}
+		userCache, err := cache.Open(os.Getenv("CACHE_DRIVER"), os.Getenv("REDIS_URL"), cacheSize)
+		if err != nil {
+			panic(err)
+		}
 
-	// Redis
-	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+		sqlRepo := repository.NewUserRepository(db, userCache, dialect)
+		userRepo = sqlRepo
+		statsRepo = repository.NewStatsRepository(db, dialect)
+		sqlSeasonRepo := repository.NewSeasonRepository(db, dialect)
+		seasonRepo = sqlSeasonRepo
+
+		// Write-behind: penulisan user diantrikan lalu disimpan per batch
+		if os.Getenv("WRITE_BEHIND") == "true" {
+			interval := 200 * time.Millisecond
+			if v, err := strconv.Atoi(os.Getenv("WRITE_BEHIND_INTERVAL_MS")); err == nil && v > 0 {
+				interval = time.Duration(v) * time.Millisecond
+			}
+			maxBatch := 500
+			if v, err := strconv.Atoi(os.Getenv("WRITE_BEHIND_MAX_BATCH")); err == nil && v > 0 {
+				maxBatch = v
+			}
+			writeBehind = repository.NewWriteBehindRepository(sqlRepo, interval, maxBatch)
+			userRepo = writeBehind
+			sqlSeasonRepo.Flush = writeBehind.Flush
+			slog.Info("Write-behind aktif", "interval", interval.String(), "max_batch", maxBatch)
+		}
+	}
 
 	// Wiring (Dependency Injection)
-	userRepo := repository.NewUserRepository(db, rdb)
-	statsRepo := repository.NewStatsRepository(db)
 	userService := service.NewUserService(userRepo)
+	// strict: key yang tidak ada di schema user ditolak (default lenient)
+	userService.StrictSchema = os.Getenv("USER_SCHEMA_MODE") == "strict"
+	// Tabel hadiah klaim (daily, weekly, ...), lihat claims.example.json
+	claimConfig := os.Getenv("CLAIM_CONFIG")
+	if claimConfig == "" {
+		claimConfig = "claims.json"
+	}
+	claims, err := service.LoadClaims(claimConfig)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		slog.Info("File config klaim tidak ada, pakai hadiah default", "path", claimConfig)
+	case err != nil:
+		panic(err)
+	default:
+		userService.Claims = claims
+		slog.Info("Config klaim dimuat", "path", claimConfig, "periods", userService.ClaimPeriods())
+	}
 	userHandler := handler.NewUserHandler(userService, statsRepo)
+	adminHandler := handler.NewAdminHandler(userService)
+	adminHandler.WriteBehind = writeBehind
+	adminHandler.SnapshotRetentionDays = snapshotRetentionDays()
+	seasonService := service.NewSeasonService(seasonRepo, userRepo)
+	seasonHandler := handler.NewSeasonHandler(seasonService)
 
 	// Server
 	e := echo.New()
+	e.Use(echoMiddleware.RequestID())
 	e.Use(echoMiddleware.Logger())
 	e.Use(echoMiddleware.Recover())
 
@@ -70,26 +148,83 @@ func main() {
 	g := e.Group("/api/features/rpg")
 	g.Use(middleware.TrafficLogger(statsRepo))
 	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.RequestContext())
 	{
 		// semua routes di sini
 		g.GET("/user/:userId", userHandler.GetUser)
 		g.POST("/user/:userId", userHandler.UpdateUser)
+		g.PATCH("/user/:userId", userHandler.PatchUser)
+		g.POST("/user/:userId/ops", userHandler.ApplyOps)
+		g.POST("/transfer", userHandler.Transfer)
+		g.POST("/tx", userHandler.ApplyTx)
+		g.GET("/user/:userId/history", userHandler.GetHistory)
 		g.GET("/leaderboard", userHandler.GetLeaderboard)
+		g.GET("/leaderboard/rank/:userId", userHandler.GetLeaderboardRank)
+		g.GET("/leaderboard/seasons", seasonHandler.ListSeasons)
+		g.GET("/leaderboard/seasons/:id", seasonHandler.GetSeason)
 		g.GET("/stats", userHandler.GetStats)
 		g.POST("/daily/:userId", userHandler.ClaimDaily)
+		g.POST("/claim/:period/:userId", userHandler.Claim)
+		g.GET("/streak/:userId", userHandler.GetStreak)
+		g.POST("/streak/freeze/:userId", userHandler.BuyStreakFreeze)
+		g.POST("/user/:userId/cooldown/:action", userHandler.UseCooldown)
+		g.GET("/user/:userId/cooldowns", userHandler.GetCooldowns)
 		g.GET("/users/afk", userHandler.GetAFKUsers)
 	}
 
-	startDailyScheduler(statsRepo)
+	// Endpoint moderator, butuh x-admin-key juga
+	admin := g.Group("/admin", middleware.AdminMiddleware())
+	{
+		admin.GET("/audit", adminHandler.GetAuditLog)
+		admin.POST("/user/:userId/restore", adminHandler.RestoreUser)
+		admin.POST("/backfill", adminHandler.StartBackfill)
+		admin.GET("/backfill", adminHandler.GetBackfill)
+		admin.GET("/write-behind", adminHandler.GetWriteBehind)
+		admin.POST("/seasons", seasonHandler.CreateSeason)
+	}
+
+	startDailyScheduler(statsRepo, userRepo, adminHandler.SnapshotRetentionDays)
+	startSeasonScheduler(seasonService)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "3902" // Fallback kalau di .env kosong, tapi default ini untuk server saya sendiri
 	}
-	e.Logger.Fatal(e.Start(":" + port))
+	go func() {
+		if err := e.Start(":" + port); err != nil && err != http.ErrServerClosed {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// Shutdown rapi: tunggu request selesai, lalu simpan antrian write-behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	slog.Info("Server berhenti...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Gagal menghentikan server", "err", err)
+	}
+	if writeBehind != nil {
+		if err := writeBehind.Close(shutdownCtx); err != nil {
+			slog.Error("Antrian write-behind tidak tersimpan semua", "err", err)
+		} else {
+			slog.Info("Antrian write-behind tersimpan")
+		}
+	}
+}
+
+// snapshotRetentionDays membaca SNAPSHOT_RETENTION_DAYS. Kosong / 0 = snapshot
+// disimpan selamanya, jadi restore tidak diam-diam kehilangan riwayat.
+func snapshotRetentionDays() int {
+	days, _ := strconv.Atoi(os.Getenv("SNAPSHOT_RETENTION_DAYS"))
+	return max(days, 0)
 }
 
-// function reset stats agar tidak menumpuk
-func startDailyScheduler(statsRepo *repository.StatsRepository) {
+// function reset stats agar tidak menumpuk, sekalian buang snapshot user
+// yang lebih lama dari retentionDays (0 = tidak dibuang)
+func startDailyScheduler(statsRepo repository.StatsStore, userRepo repository.UserStore, retentionDays int) {
 	go func() {
 		for {
 			now := time.Now()
@@ -104,6 +239,33 @@ func startDailyScheduler(statsRepo *repository.StatsRepository) {
 			} else {
 				slog.Info("Traffic Stats berhasil dikosongkan.")
 			}
+
+			if retentionDays > 0 {
+				before := time.Now().AddDate(0, 0, -retentionDays)
+				if n, err := userRepo.PruneSnapshots(before); err != nil {
+					slog.Error("Gagal hapus snapshot lama", "err", err)
+				} else {
+					slog.Info("Snapshot user lama dihapus", "count", n)
+				}
+			}
+		}
+	}()
+}
+
+// startSeasonScheduler memulai dan mengarsip season leaderboard tepat waktu
+// (dicek ulang paling lama tiap menit dan setiap ada season baru)
+func startSeasonScheduler(seasonService *service.SeasonService) {
+	go func() {
+		for {
+			wait := time.Minute
+			next := seasonService.RunSeasons(context.Background(), time.Now())
+			if !next.IsZero() && time.Until(next) < wait {
+				wait = max(time.Until(next), time.Second)
+			}
+			select {
+			case <-time.After(wait):
+			case <-seasonService.Changed():
+			}
 		}
 	}()
 }
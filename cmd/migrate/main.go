@@ -0,0 +1,90 @@
+package main
+
+import (
+	"Berpg/internal/migration"
+	"Berpg/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Pemakaian: migrate <perintah>
+  up        jalankan semua migration yang belum dijalankan
+  down [n]  batalkan n migration terakhir (default 1)
+  status    tampilkan daftar migration`
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("File .env tidak ditemukan!")
+	}
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Sama dengan DB yang dipakai cmd/api (DB_DRIVER dan DATABASE_URL)
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "memory" {
+		fmt.Println("DB_DRIVER=memory tidak punya migration.")
+		os.Exit(2)
+	}
+	db, dialect, err := repository.OpenDB(driver, os.Getenv("DATABASE_URL"))
+	exitOnError(err)
+	defer db.Close()
+
+	ctx := context.Background()
+	migrator := migration.NewMigrator(db.Write, dialect)
+
+	switch os.Args[1] {
+	case "up":
+		done, err := migrator.Up(ctx)
+		printMigrations("up", done)
+		exitOnError(err)
+		if len(done) == 0 {
+			fmt.Println("Tidak ada migration baru.")
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				fmt.Println("Jumlah langkah down harus angka >= 1.")
+				os.Exit(2)
+			}
+		}
+		done, err := migrator.Down(ctx, steps)
+		printMigrations("down", done)
+		exitOnError(err)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		exitOnError(err)
+		for _, s := range statuses {
+			applied := "belum"
+			if s.AppliedAt > 0 {
+				applied = time.UnixMilli(s.AppliedAt).Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-28s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func printMigrations(direction string, migrations []migration.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s  %04d_%s\n", direction, m.Version, m.Name)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Println("Gagal:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache adalah penyimpanan key-value sementara di depan database. Cache
+// boleh kehilangan isi kapan saja, jadi pemanggil harus tetap benar kalau
+// Get selalu miss dan Set/Del gagal.
+type Cache interface {
+	// Get mengembalikan ok=false kalau key tidak ada atau sudah kedaluwarsa
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// Open membuat cache sesuai CACHE_DRIVER:
+//   - "redis" (default): Redis di redisURL, dengan fallback LRU di memori
+//     proses selama Redis tidak bisa dihubungi
+//   - "memory": LRU di memori proses saja
+//   - "none": tanpa cache, semua pembacaan langsung ke database
+//
+// size adalah kapasitas LRU (jumlah key).
+func Open(driver, redisURL string, size int) (Cache, error) {
+	switch driver {
+	case "", "redis":
+		opts := &redis.Options{Addr: "localhost:6379"}
+		if redisURL != "" {
+			var err error
+			if opts, err = redis.ParseURL(redisURL); err != nil {
+				return nil, err
+			}
+		}
+		// Timeout pendek: kalau Redis lambat lebih baik langsung fallback
+		opts.DialTimeout = 500 * time.Millisecond
+		opts.ReadTimeout = 500 * time.Millisecond
+		opts.WriteTimeout = 500 * time.Millisecond
+		opts.MaxRetries = 1
+		return NewFailoverCache(NewRedisCache(redis.NewClient(opts)), NewLRUCache(size)), nil
+	case "memory":
+		return NewLRUCache(size), nil
+	case "none":
+		return NoopCache{}, nil
+	default:
+		return nil, fmt.Errorf("CACHE_DRIVER tidak dikenal: %s", driver)
+	}
+}
+
+// NoopCache tidak menyimpan apa pun (selalu miss)
+type NoopCache struct{}
+
+func (NoopCache) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (NoopCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return nil
+}
+
+func (NoopCache) Del(ctx context.Context, keys ...string) error {
+	return nil
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	type step struct {
+		op      string // set, get, del
+		key     string
+		value   string
+		ttl     time.Duration
+		wantOK  bool
+		wantVal string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"set lalu get", []step{
+			{op: "set", key: "a", value: "1"},
+			{op: "get", key: "a", wantOK: true, wantVal: "1"},
+			{op: "get", key: "b"},
+		}},
+		{"timpa nilai", []step{
+			{op: "set", key: "a", value: "1"},
+			{op: "set", key: "a", value: "2"},
+			{op: "get", key: "a", wantOK: true, wantVal: "2"},
+		}},
+		{"buang yang paling lama tidak dipakai", []step{
+			{op: "set", key: "a", value: "1"},
+			{op: "set", key: "b", value: "2"},
+			{op: "get", key: "a", wantOK: true, wantVal: "1"},
+			{op: "set", key: "c", value: "3"},
+			{op: "get", key: "b"},
+			{op: "get", key: "a", wantOK: true, wantVal: "1"},
+			{op: "get", key: "c", wantOK: true, wantVal: "3"},
+		}},
+		{"del", []step{
+			{op: "set", key: "a", value: "1"},
+			{op: "del", key: "a"},
+			{op: "get", key: "a"},
+		}},
+		{"ttl kedaluwarsa", []step{
+			{op: "set", key: "a", value: "1", ttl: time.Nanosecond},
+			{op: "get", key: "a"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRUCache(2)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "set":
+					c.Set(ctx, s.key, s.value, s.ttl)
+					if s.ttl > 0 {
+						time.Sleep(time.Millisecond)
+					}
+				case "del":
+					c.Del(ctx, s.key)
+				case "get":
+					val, ok, err := c.Get(ctx, s.key)
+					if err != nil || ok != s.wantOK || val != s.wantVal {
+						t.Errorf("langkah %d get %s = %q %v %v, mau %q %v", i, s.key, val, ok, err, s.wantVal, s.wantOK)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		driver  string
+		wantErr bool
+	}{
+		{"memory", false},
+		{"none", false},
+		{"memcached", true},
+	}
+	for _, tt := range tests {
+		if _, err := Open(tt.driver, "", 10); (err != nil) != tt.wantErr {
+			t.Errorf("Open(%q) err = %v", tt.driver, err)
+		}
+	}
+}
+
+// Redis yang tidak bisa dihubungi: semua operasi pindah ke LRU dan key yang
+// ditulis selama itu diingat untuk dihapus dari Redis saat pulih
+func TestFailoverCacheRedisDown(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	c := NewFailoverCache(NewRedisCache(client), NewLRUCache(10))
+
+	if c.Healthy() {
+		t.Fatal("Redis mati tapi Healthy true")
+	}
+	if err := c.Set(ctx, "user:a", "1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if val, ok, err := c.Get(ctx, "user:a"); err != nil || !ok || val != "1" {
+		t.Errorf("get = %q %v %v", val, ok, err)
+	}
+	if err := c.Del(ctx, "user:b"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	_, dirtyA := c.dirty["user:a"]
+	_, dirtyB := c.dirty["user:b"]
+	maxTTL := c.maxTTL
+	c.mu.Unlock()
+	if !dirtyA || !dirtyB || maxTTL != time.Minute {
+		t.Errorf("dirty a=%v b=%v maxTTL=%v", dirtyA, dirtyB, maxTTL)
+	}
+	if err := c.recover(ctx); err == nil || c.Healthy() {
+		t.Errorf("recover saat Redis masih mati = %v", err)
+	}
+	c.mu.Lock()
+	remaining := len(c.dirty)
+	c.mu.Unlock()
+	if remaining != 2 {
+		t.Errorf("key basi yang diingat setelah recover gagal = %d, mau 2", remaining)
+	}
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache adalah Cache di atas Redis, dibagi semua instance server
+type RedisCache struct {
+	Client *redis.Client
+}
+
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{Client: client}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	val, err := c.Client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.Client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.Client.Del(ctx, keys...).Err()
+}
+
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.Client.Ping(ctx).Err()
+}
+
+const (
+	// jeda health check Redis
+	healthCheckInterval = 5 * time.Second
+	// batas key yang diingat selama Redis mati (lihat FailoverCache.dirty)
+	maxDirtyKeys = 100000
+)
+
+// FailoverCache memakai Redis selama sehat. Begitu ada error (atau health
+// check gagal), semua operasi pindah ke LRU di memori proses tanpa menunggu
+// timeout Redis lagi, dan health check di background mencoba Redis ulang.
+//
+// Selama Redis mati, isi Redis bisa basi (penulisan hanya masuk ke LRU).
+// Karena itu key yang di-Set/Del selama mati diingat dan dihapus dari Redis
+// sebelum Redis dipakai lagi. Kalau key yang diingat terlalu banyak, Redis
+// baru dipakai lagi setelah semua entry lama pasti kedaluwarsa (TTL terbesar
+// yang pernah dipakai).
+type FailoverCache struct {
+	Primary  *RedisCache
+	Fallback *LRUCache
+
+	mu        sync.Mutex
+	healthy   bool
+	downSince time.Time
+	dirty     map[string]struct{}
+	overflow  bool
+	maxTTL    time.Duration
+}
+
+func NewFailoverCache(primary *RedisCache, fallback *LRUCache) *FailoverCache {
+	c := &FailoverCache{
+		Primary:  primary,
+		Fallback: fallback,
+		healthy:  true,
+		dirty:    make(map[string]struct{}),
+	}
+	// Cek sekali di awal supaya request pertama tidak kena timeout
+	if err := primary.Ping(context.Background()); err != nil {
+		c.markDown(err)
+	}
+	go c.healthLoop()
+	return c
+}
+
+// Healthy melaporkan apakah Redis sedang dipakai
+func (c *FailoverCache) Healthy() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.healthy
+}
+
+func (c *FailoverCache) Get(ctx context.Context, key string) (string, bool, error) {
+	if c.Healthy() {
+		val, ok, err := c.Primary.Get(ctx, key)
+		if !c.failed(ctx, err) {
+			return val, ok, err
+		}
+	}
+	return c.Fallback.Get(ctx, key)
+}
+
+func (c *FailoverCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	if ttl > c.maxTTL {
+		c.maxTTL = ttl
+	}
+	c.mu.Unlock()
+
+	if c.Healthy() {
+		err := c.Primary.Set(ctx, key, value, ttl)
+		if !c.failed(ctx, err) {
+			return err
+		}
+	}
+	return c.onFallback(func() { c.Fallback.Set(ctx, key, value, ttl) }, key)
+}
+
+func (c *FailoverCache) Del(ctx context.Context, keys ...string) error {
+	if c.Healthy() {
+		err := c.Primary.Del(ctx, keys...)
+		if !c.failed(ctx, err) {
+			return err
+		}
+	}
+	return c.onFallback(func() { c.Fallback.Del(ctx, keys...) }, keys...)
+}
+
+// failed menandai Redis mati kalau err berasal dari Redis (bukan karena
+// context pemanggil dibatalkan)
+func (c *FailoverCache) failed(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	c.markDown(err)
+	return true
+}
+
+// onFallback menjalankan fn ke LRU dan mengingat keys sebagai basi di Redis.
+// Kalau Redis ternyata sudah pulih, tidak ada yang perlu diingat tapi entry
+// Redis tetap dihapus supaya tidak basi.
+func (c *FailoverCache) onFallback(fn func(), keys ...string) error {
+	c.mu.Lock()
+	if !c.healthy {
+		fn()
+		for _, key := range keys {
+			if len(c.dirty) >= maxDirtyKeys {
+				c.overflow = true
+				break
+			}
+			c.dirty[key] = struct{}{}
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+	return c.Primary.Del(context.Background(), keys...)
+}
+
+func (c *FailoverCache) markDown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.healthy {
+		return
+	}
+	c.healthy = false
+	c.downSince = time.Now()
+	slog.Warn("Redis tidak bisa dihubungi, cache pindah ke memori", "err", err)
+}
+
+func (c *FailoverCache) healthLoop() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckInterval)
+		err := c.Primary.Ping(ctx)
+		if err != nil {
+			c.markDown(err)
+		} else if !c.Healthy() {
+			if err := c.recover(ctx); err != nil {
+				slog.Warn("Redis belum bisa dipakai lagi", "err", err)
+			}
+		}
+		cancel()
+	}
+}
+
+// errStaleEntries: terlalu banyak key basi, tunggu entry lama kedaluwarsa
+var errStaleEntries = errors.New("menunggu entry Redis lama kedaluwarsa")
+
+// recover menghapus key basi dari Redis lalu kembali memakai Redis
+func (c *FailoverCache) recover(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.overflow && time.Since(c.downSince) < c.maxTTL {
+			c.mu.Unlock()
+			return errStaleEntries
+		}
+		if len(c.dirty) == 0 || c.overflow {
+			// Dikunci sampai selesai supaya tidak ada penulisan LRU yang
+			// terlewat di antara pengecekan dan perpindahan
+			c.healthy = true
+			c.overflow = false
+			c.dirty = make(map[string]struct{})
+			c.Fallback.Clear()
+			c.mu.Unlock()
+			slog.Info("Redis pulih, cache kembali ke Redis")
+			return nil
+		}
+		keys := make([]string, 0, len(c.dirty))
+		for key := range c.dirty {
+			keys = append(keys, key)
+		}
+		c.dirty = make(map[string]struct{})
+		c.mu.Unlock()
+
+		if err := c.Primary.Del(ctx, keys...); err != nil {
+			// Kembalikan supaya dicoba lagi di health check berikutnya
+			c.mu.Lock()
+			for _, key := range keys {
+				c.dirty[key] = struct{}{}
+			}
+			c.mu.Unlock()
+			return err
+		}
+	}
+}
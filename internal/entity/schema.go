@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema dokumen user diturunkan dari GetDefaultUserMap: tipe tiap field
+// mengikuti nilai default-nya, angka tidak boleh di bawah 0 (atau di bawah
+// default-nya kalau default negatif, misal afk = -1), dan beberapa field
+// string hanya boleh berisi nilai tertentu.
+
+// Nilai yang diizinkan untuk field string tertentu
+var userEnums = map[string][]string{
+	"vip":    {"tidak", "ya"},
+	"rtrofi": {"perunggu", "perak", "emas", "diamond"},
+}
+
+// FieldError adalah satu pelanggaran schema pada path tertentu
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type fieldKind int
+
+const (
+	kindAny fieldKind = iota // nilai default null, tipe bebas
+	kindNumber
+	kindString
+	kindBool
+	kindObject
+)
+
+type fieldSchema struct {
+	kind     fieldKind
+	nullable bool
+	min      float64
+	enum     []string
+	fields   map[string]*fieldSchema // nil untuk object bebas (misal inventory)
+}
+
+var userSchema = deriveSchema("", GetDefaultUserMap())
+
+func deriveSchema(path string, defaults map[string]interface{}) *fieldSchema {
+	schema := &fieldSchema{kind: kindObject, fields: make(map[string]*fieldSchema)}
+	if len(defaults) == 0 {
+		schema.fields = nil // object kosong = isi bebas
+		return schema
+	}
+
+	for k, v := range defaults {
+		fieldPath := k
+		if path != "" {
+			fieldPath = path + "." + k
+		}
+		schema.fields[k] = deriveField(fieldPath, v)
+	}
+	return schema
+}
+
+func deriveField(path string, v interface{}) *fieldSchema {
+	switch val := v.(type) {
+	case nil:
+		return &fieldSchema{kind: kindAny, nullable: true}
+	case bool:
+		return &fieldSchema{kind: kindBool}
+	case string:
+		return &fieldSchema{kind: kindString, enum: userEnums[path]}
+	case float64:
+		return &fieldSchema{kind: kindNumber, min: math.Min(0, val)}
+	case int:
+		return &fieldSchema{kind: kindNumber, min: math.Min(0, float64(val))}
+	case map[string]interface{}:
+		return deriveSchema(path, val)
+	}
+	return &fieldSchema{kind: kindAny, nullable: true}
+}
+
+func init() {
+	// "id" tidak ada di default tapi selalu ikut tersimpan
+	userSchema.fields["id"] = &fieldSchema{kind: kindString}
+}
+
+// ValidateUserMap memeriksa dokumen user terhadap schema. Dengan strict, key
+// yang tidak dikenal juga dianggap salah. Hasil urut per path.
+func ValidateUserMap(doc map[string]interface{}, strict bool) []FieldError {
+	var errs []FieldError
+	userSchema.validateObject("", doc, strict, &errs)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// IsNumericPath melaporkan apakah path (misal "money", "rpg.exp") adalah
+// field angka di schema user
+func IsNumericPath(path string) bool {
+	schema := userSchema
+	for _, key := range strings.Split(path, ".") {
+		if schema.fields == nil {
+			return false
+		}
+		field, ok := schema.fields[key]
+		if !ok {
+			return false
+		}
+		schema = field
+	}
+	return schema.kind == kindNumber
+}
+
+// NumericPaths mengembalikan semua path field angka di schema user, urut
+func NumericPaths() []string {
+	var paths []string
+	var walk func(prefix string, s *fieldSchema)
+	walk = func(prefix string, s *fieldSchema) {
+		for key, field := range s.fields {
+			path := prefix + key
+			switch field.kind {
+			case kindNumber:
+				paths = append(paths, path)
+			case kindObject:
+				walk(path+".", field)
+			}
+		}
+	}
+	walk("", userSchema)
+	sort.Strings(paths)
+	return paths
+}
+
+func (s *fieldSchema) validateObject(path string, obj map[string]interface{}, strict bool, errs *[]FieldError) {
+	if s.fields == nil {
+		return
+	}
+	for k, v := range obj {
+		fieldPath := k
+		if path != "" {
+			fieldPath = path + "." + k
+		}
+
+		field, known := s.fields[k]
+		if !known {
+			if strict {
+				*errs = append(*errs, FieldError{Path: fieldPath, Message: "field tidak dikenal"})
+			}
+			continue
+		}
+		field.validate(fieldPath, v, strict, errs)
+	}
+}
+
+func (s *fieldSchema) validate(path string, v interface{}, strict bool, errs *[]FieldError) {
+	fail := func(msg string) {
+		*errs = append(*errs, FieldError{Path: path, Message: msg})
+	}
+
+	if v == nil {
+		if !s.nullable {
+			fail("tidak boleh null")
+		}
+		return
+	}
+
+	switch s.kind {
+	case kindNumber:
+		num, ok := toFloat(v)
+		if !ok {
+			fail("harus angka")
+			return
+		}
+		if math.IsNaN(num) || math.IsInf(num, 0) {
+			fail("harus angka yang valid")
+		} else if num < s.min {
+			if s.min == 0 {
+				fail("tidak boleh negatif")
+			} else {
+				fail(fmt.Sprintf("minimal %g", s.min))
+			}
+		}
+	case kindString:
+		str, ok := v.(string)
+		if !ok {
+			fail("harus string")
+			return
+		}
+		if len(s.enum) > 0 && !contains(s.enum, str) {
+			fail("harus salah satu dari: " + strings.Join(s.enum, ", "))
+		}
+	case kindBool:
+		if _, ok := v.(bool); !ok {
+			fail("harus boolean")
+		}
+	case kindObject:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("harus object")
+			return
+		}
+		s.validateObject(path, obj, strict, errs)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
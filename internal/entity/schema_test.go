@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateUserMap(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    map[string]interface{}
+		strict bool
+		want   []FieldError
+	}{
+		{"default valid", GetDefaultUserMap(), true, nil},
+		{"angka negatif", map[string]interface{}{"money": -1.0}, false,
+			[]FieldError{{Path: "money", Message: "tidak boleh negatif"}}},
+		{"min dari default negatif", map[string]interface{}{"afk": -2.0}, false,
+			[]FieldError{{Path: "afk", Message: "minimal -1"}}},
+		{"afk -1 boleh", map[string]interface{}{"afk": -1.0}, false, nil},
+		{"tipe salah", map[string]interface{}{"money": "banyak", "banned": 1.0}, false,
+			[]FieldError{{Path: "banned", Message: "harus boolean"}, {Path: "money", Message: "harus angka"}}},
+		{"enum", map[string]interface{}{"vip": "mungkin"}, false,
+			[]FieldError{{Path: "vip", Message: "harus salah satu dari: tidak, ya"}}},
+		{"nested", map[string]interface{}{"rpg": map[string]interface{}{"level": "x"}}, false,
+			[]FieldError{{Path: "rpg.level", Message: "harus angka"}}},
+		{"nullable", map[string]interface{}{"proposalFrom": nil, "jail": map[string]interface{}{"reason": nil}}, false, nil},
+		{"tidak boleh null", map[string]interface{}{"money": nil}, false,
+			[]FieldError{{Path: "money", Message: "tidak boleh null"}}},
+		{"inventory bebas", map[string]interface{}{"rpg": map[string]interface{}{"inventory": map[string]interface{}{"x": "y"}}}, true, nil},
+		{"key asing lolos tanpa strict", map[string]interface{}{"custom": 1.0}, false, nil},
+		{"key asing ditolak strict", map[string]interface{}{"custom": 1.0}, true,
+			[]FieldError{{Path: "custom", Message: "field tidak dikenal"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateUserMap(tt.doc, tt.strict)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateUserMap = %v, mau %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNumericPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"money", true},
+		{"rpg.exp", true},
+		{"motor.Bensin", true},
+		{"username", false},
+		{"rpg", false},
+		{"rpg.inventory.potion", false},
+		{"tidakada", false},
+	}
+	for _, tt := range tests {
+		if got := IsNumericPath(tt.path); got != tt.want {
+			t.Errorf("IsNumericPath(%q) = %v, mau %v", tt.path, got, tt.want)
+		}
+	}
+}
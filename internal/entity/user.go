@@ -1,40 +1,461 @@
 package entity
 
+// RpgStats adalah object nested "rpg"
 type RpgStats struct {
-	Level     int     `json:"level"`
-	Exp       int     `json:"exp"`
-	Health    int     `json:"health"`
-	Mana      int     `json:"mana"`
+	Level     float64 `json:"level"`
+	Exp       float64 `json:"exp"`
+	Health    float64 `json:"health"`
+	Mana      float64 `json:"mana"`
 	Inventory MapData `json:"inventory"`
+
+	// Key lain di dalam "rpg" yang tidak dikenal
+	Extra map[string]interface{} `json:"-"`
+
+	missing []string
 }
 
+// JailStats adalah object nested "jail"
 type JailStats struct {
-	Status bool   `json:"status"`
-	Reason string `json:"reason,omitempty"`
-	Until  int64  `json:"until"`
+	Status bool    `json:"status"`
+	Reason *string `json:"reason"`
+	Until  float64 `json:"until"`
+
+	Extra map[string]interface{} `json:"-"`
+
+	missing []string
+}
+
+// MotorStats adalah object nested "motor"
+type MotorStats struct {
+	Status     bool    `json:"status"`
+	MotorGrade string  `json:"motorGrade"`
+	Name       string  `json:"Name"`
+	Bensin     float64 `json:"Bensin"`
+	Durability float64 `json:"Durability"`
+
+	Extra map[string]interface{} `json:"-"`
+
+	missing []string
 }
 
 type MapData map[string]interface{}
 
+// User adalah dokumen user lengkap. Setiap key di GetDefaultUserMap punya
+// field sendiri; key lain (dari versi bot lama/baru) masuk ke Extra dan tetap
+// ikut tersimpan, jadi format JSON-nya sama dengan dokumen map sebelumnya.
+// Semua angka float64 karena begitulah bentuknya di JSON.
 type User struct {
 	// Identity
-	UserID   string `json:"userId"` // key
-	Username string `json:"username"`
-	Name     string `json:"name"`
-	Age      int    `json:"age"`
-
-	// Core Stats
-	Money      float64 `json:"money"`
-	Bank       float64 `json:"bank"`
-	Diamond    float64 `json:"diamond"`
+	ID         string  `json:"id,omitempty"` // key
+	Username   string  `json:"username"`
+	Name       string  `json:"name"`
+	Age        float64 `json:"age"`
 	Registered bool    `json:"registered"`
-	RegTime    int64   `json:"regTime"`
+	RegTime    float64 `json:"regTime"`
+	Money      float64 `json:"money"`
+	Role       string  `json:"role"`
+	Location   string  `json:"location"`
+
+	// --- Cooldowns & Activity ---
+	LastDaily     float64 `json:"lastDaily"`
+	LastWeekly    float64 `json:"lastWeekly"`
+	Limit         float64 `json:"limit"`
+	Pasangan      string  `json:"pasangan"`
+	ProposalFrom  *string `json:"proposalFrom"`
+	Sahabat       string  `json:"sahabat"`
+	Lastjobkerja  float64 `json:"lastjobkerja"`
+	Lastjobchange float64 `json:"lastjobchange"`
+
+	// --- Daily Streak ---
+	DailyStreak     float64 `json:"dailyStreak"`     // hari kalender berturut-turut klaim daily
+	BestDailyStreak float64 `json:"bestDailyStreak"` // dailyStreak tertinggi
+	StreakFreeze    float64 `json:"streakfreeze"`    // item pelindung streak, 1 per hari terlewat
+
+	// --- Nested Objects (PENTING) ---
+	Rpg   RpgStats   `json:"rpg"`
+	Jail  JailStats  `json:"jail"`
+	Motor MotorStats `json:"motor"`
+
+	// --- Transport & Jobs ---
+	Taxi          float64 `json:"taxi"`
+	Lasttaxi      float64 `json:"lasttaxi"`
+	Lastyoutuber  float64 `json:"lastyoutuber"`
+	Subscribers   float64 `json:"subscribers"`
+	Viewers       float64 `json:"viewers"`
+	Like          float64 `json:"like"`
+	PlayButton    float64 `json:"playButton"`
+	Saldo         float64 `json:"saldo"`
+	Pengeluaran   float64 `json:"pengeluaran"`
+	Energi        float64 `json:"energi"`
+	Power         float64 `json:"power"`
+	Title         string  `json:"title"`
+	Haus          float64 `json:"haus"`
+	Laper         float64 `json:"laper"`
+	Tprem         float64 `json:"tprem"`
+	Stamina       float64 `json:"stamina"`
+	Follow        float64 `json:"follow"`
+	Lastfollow    float64 `json:"lastfollow"`
+	Followers     float64 `json:"followers"`
+	Titlein       string  `json:"titlein"`
+	Ultah         string  `json:"ultah"`
+	Pc            float64 `json:"pc"`
+	Coin          float64 `json:"coin"`
+	Atm           float64 `json:"atm"`
+	Skata         float64 `json:"skata"`
+	Tigame        float64 `json:"tigame"`
+	Lastclaim     float64 `json:"lastclaim"`
+	Judilast      float64 `json:"judilast"`
+	Lastnambang   float64 `json:"lastnambang"`
+	Lastnebang    float64 `json:"lastnebang"`
+	Lastmulung    float64 `json:"lastmulung"`
+	Lastkerja     float64 `json:"lastkerja"`
+	Lastmaling    float64 `json:"lastmaling"`
+	Lastbunuhi    float64 `json:"lastbunuhi"`
+	Lastbisnis    float64 `json:"lastbisnis"`
+	Lastberbisnis float64 `json:"lastberbisnis"`
+	Bisnis        float64 `json:"bisnis"`
+	Berbisnis     float64 `json:"berbisnis"`
+	Lastmancing   float64 `json:"lastmancing"`
+
+	// --- Items & Inventory ---
+	Pancing        float64 `json:"pancing"`
+	Pancingan      float64 `json:"pancingan"`
+	TotalPancingan float64 `json:"totalPancingan"`
+	Kardus         float64 `json:"kardus"`
+	Botol          float64 `json:"botol"`
+	Kaleng         float64 `json:"kaleng"`
+	Litecoin       float64 `json:"litecoin"`
+	Chip           float64 `json:"chip"`
+	Tiketcoin      float64 `json:"tiketcoin"`
+	Poin           float64 `json:"poin"`
+	Bank           float64 `json:"bank"`
+	Balance        float64 `json:"balance"`
+	Diamond        float64 `json:"diamond"`
+	Emerald        float64 `json:"emerald"`
+	Rock           float64 `json:"rock"`
+	Wood           float64 `json:"wood"`
+	Berlian        float64 `json:"berlian"`
+	Iron           float64 `json:"iron"`
+	Emas           float64 `json:"emas"`
+	Common         float64 `json:"common"`
+	Uncommon       float64 `json:"uncommon"`
+	Mythic         float64 `json:"mythic"`
+	Legendary      float64 `json:"legendary"`
+	Rumahsakit     float64 `json:"rumahsakit"`
+	Tambang        float64 `json:"tambang"`
+	Camptroops     float64 `json:"camptroops"`
+	Pertanian      float64 `json:"pertanian"`
+	Fortress       float64 `json:"fortress"`
+	Trofi          float64 `json:"trofi"`
+	Rtrofi         string  `json:"rtrofi"`
+	Makanan        float64 `json:"makanan"`
+	Troopcamp      float64 `json:"troopcamp"`
+	Shield         float64 `json:"shield"`
+	Arlok          float64 `json:"arlok"`
+	Ojekk          float64 `json:"ojekk"`
+	Ojek           float64 `json:"ojek"`
+	Lastngewe      float64 `json:"lastngewe"`
+	Ngewe          float64 `json:"ngewe"`
+	Polisi         float64 `json:"polisi"`
+	Pedagang       float64 `json:"pedagang"`
+	Dokter         float64 `json:"dokter"`
+	Petani         float64 `json:"petani"`
+	Montir         float64 `json:"montir"`
+	Kuli           float64 `json:"kuli"`
+	Korbanngocok   float64 `json:"korbanngocok"`
+	Coal           float64 `json:"coal"`
+	Korekapi       float64 `json:"korekapi"`
 
-	// Nested Objects
-	Rpg       RpgStats  `json:"rpg"`
-	Jail      JailStats `json:"jail"`
-	Items     MapData   `json:"items"`
-	LastTimes MapData   `json:"last_times"`
+	// --- Foods ---
+	Ayambakar       float64 `json:"ayambakar"`
+	Gulai           float64 `json:"gulai"`
+	Rendang         float64 `json:"rendang"`
+	Ayamgoreng      float64 `json:"ayamgoreng"`
+	Oporayam        float64 `json:"oporayam"`
+	Steak           float64 `json:"steak"`
+	Babipanggang    float64 `json:"babipanggang"`
+	Ikanbakar       float64 `json:"ikanbakar"`
+	Lelebakar       float64 `json:"lelebakar"`
+	Nilabakar       float64 `json:"nilabakar"`
+	Bawalbakar      float64 `json:"bawalbakar"`
+	Udangbakar      float64 `json:"udangbakar"`
+	Pausbakar       float64 `json:"pausbakar"`
+	Kepitingbakar   float64 `json:"kepitingbakar"`
+	Soda            float64 `json:"soda"`
+	Vodka           float64 `json:"vodka"`
+	Ganja           float64 `json:"ganja"`
+	Bandage         float64 `json:"bandage"`
+	Sushi           float64 `json:"sushi"`
+	Roti            float64 `json:"roti"`
+	Ramuan          float64 `json:"ramuan"`
+	Lastramuanclaim float64 `json:"lastramuanclaim"`
+	Gems            float64 `json:"gems"`
+	Cupon           float64 `json:"cupon"`
+	Lastgemsclaim   float64 `json:"lastgemsclaim"`
+	Eleksirb        float64 `json:"eleksirb"`
+	Penduduk        float64 `json:"penduduk"`
+	Archer          float64 `json:"archer"`
+	Shadow          float64 `json:"shadow"`
+
+	// --- Claims ---
+	Laststringclaim  float64 `json:"laststringclaim"`
+	Lastpotionclaim  float64 `json:"lastpotionclaim"`
+	Lastswordclaim   float64 `json:"lastswordclaim"`
+	Lastweaponclaim  float64 `json:"lastweaponclaim"`
+	Lastironclaim    float64 `json:"lastironclaim"`
+	Lastslot         float64 `json:"lastslot"`
+	Lastmancingclaim float64 `json:"lastmancingclaim"`
+
+	// --- Fishing Catches ---
+	Anakpancingan float64 `json:"anakpancingan"`
+	As            float64 `json:"as"`
+	Paus          float64 `json:"paus"`
+	Kepiting      float64 `json:"kepiting"`
+	Gurita        float64 `json:"gurita"`
+	Cumi          float64 `json:"cumi"`
+	Buntal        float64 `json:"buntal"`
+	Dory          float64 `json:"dory"`
+	Lumba         float64 `json:"lumba"`
+	Lobster       float64 `json:"lobster"`
+	Hiu           float64 `json:"hiu"`
+	Lele          float64 `json:"lele"`
+	Nila          float64 `json:"nila"`
+	Bawal         float64 `json:"bawal"`
+	Udang         float64 `json:"udang"`
+	Ikan          float64 `json:"ikan"`
+	Orca          float64 `json:"orca"`
+
+	// --- Animals ---
+	Banteng   float64 `json:"banteng"`
+	Harimau   float64 `json:"harimau"`
+	Gajah     float64 `json:"gajah"`
+	Kambing   float64 `json:"kambing"`
+	Panda     float64 `json:"panda"`
+	Buaya     float64 `json:"buaya"`
+	Kerbau    float64 `json:"kerbau"`
+	Sapi      float64 `json:"sapi"`
+	Monyet    float64 `json:"monyet"`
+	Babihutan float64 `json:"babihutan"`
+	Babi      float64 `json:"babi"`
+	Ayam      float64 `json:"ayam"`
+	Ayamb     float64 `json:"ayamb"`
+	Ayamg     float64 `json:"ayamg"`
+	Ssapi     float64 `json:"ssapi"`
+	Sapir     float64 `json:"sapir"`
+	Leleb     float64 `json:"leleb"`
+	Leleg     float64 `json:"leleg"`
+	Esteh     float64 `json:"esteh"`
+	Pet       float64 `json:"pet"`
+	Potion    float64 `json:"potion"`
+	Sampah    float64 `json:"sampah"`
+
+	// --- Mythical Pets ---
+	Kucing            float64 `json:"kucing"`
+	Kucinglastclaim   float64 `json:"kucinglastclaim"`
+	Kucingexp         float64 `json:"kucingexp"`
+	Kuda              float64 `json:"kuda"`
+	Kudalastclaim     float64 `json:"kudalastclaim"`
+	Rubah             float64 `json:"rubah"`
+	Rubahlastclaim    float64 `json:"rubahlastclaim"`
+	Rubahexp          float64 `json:"rubahexp"`
+	Anjing            float64 `json:"anjing"`
+	Anjinglastclaim   float64 `json:"anjinglastclaim"`
+	Anjingexp         float64 `json:"anjingexp"`
+	Naga              float64 `json:"naga"`
+	Nagalastclaim     float64 `json:"nagalastclaim"`
+	Griffin           float64 `json:"griffin"`
+	Griffinlastclaim  float64 `json:"griffinlastclaim"`
+	Centaur           float64 `json:"centaur"`
+	Fightnaga         float64 `json:"fightnaga"`
+	Centaurlastclaim  float64 `json:"centaurlastclaim"`
+	Serigala          float64 `json:"serigala"`
+	Serigalalastclaim float64 `json:"serigalalastclaim"`
+	Serigalaexp       float64 `json:"serigalaexp"`
+	Phonix            float64 `json:"phonix"`
+	Phonixlastclaim   float64 `json:"phonixlastclaim"`
+	Phonixexp         float64 `json:"phonixexp"`
+
+	// --- Pet Food ---
+	Makanannaga     float64 `json:"makanannaga"`
+	Makananphonix   float64 `json:"makananphonix"`
+	Makanancentaur  float64 `json:"makanancentaur"`
+	Makananserigala float64 `json:"makananserigala"`
+	Makananpet      float64 `json:"makananpet"`
+	Anakkucing      float64 `json:"anakkucing"`
+	Anakkuda        float64 `json:"anakkuda"`
+	Anakrubah       float64 `json:"anakrubah"`
+	Anakanjing      float64 `json:"anakanjing"`
+	Anakserigala    float64 `json:"anakserigala"`
+	Anaknaga        float64 `json:"anaknaga"`
+	Anakphonix      float64 `json:"anakphonix"`
+	Anakgriffin     float64 `json:"anakgriffin"`
+	Anakkyubi       float64 `json:"anakkyubi"`
+	Anakcentaur     float64 `json:"anakcentaur"`
+
+	// --- System ---
+	BannedReason      string  `json:"BannedReason"`
+	BannedTime        float64 `json:"bannedTime"`
+	Banned            bool    `json:"banned"`
+	Warn              float64 `json:"warn"`
+	Afk               float64 `json:"afk"`
+	AfkReason         string  `json:"afkReason"`
+	Antispam          float64 `json:"antispam"`
+	Antispamlastclaim float64 `json:"antispamlastclaim"`
+
+	// --- Materials & Tools ---
+	Kayu                 float64 `json:"kayu"`
+	Batu                 float64 `json:"batu"`
+	String               float64 `json:"string"`
+	Umpan                float64 `json:"umpan"`
+	Armor                float64 `json:"armor"`
+	Armordurability      float64 `json:"armordurability"`
+	Weapon               float64 `json:"weapon"`
+	Weapondurability     float64 `json:"weapondurability"`
+	Sword                float64 `json:"sword"`
+	Sworddurability      float64 `json:"sworddurability"`
+	Pickaxe              float64 `json:"pickaxe"`
+	Pickaxedurability    float64 `json:"pickaxedurability"`
+	Fishingrod           float64 `json:"fishingrod"`
+	Fishingroddurability float64 `json:"fishingroddurability"`
+	Katana               float64 `json:"katana"`
+	Katanadurability     float64 `json:"katanadurability"`
+	Bow                  float64 `json:"bow"`
+	Bowdurability        float64 `json:"bowdurability"`
+	Kapak                float64 `json:"kapak"`
+	Kapakdurability      float64 `json:"kapakdurability"`
+	Axe                  float64 `json:"axe"`
+	Axedurability        float64 `json:"axedurability"`
+	Pisau                float64 `json:"pisau"`
+	Pisaudurability      float64 `json:"pisaudurability"`
+
+	// --- Jobs Counters ---
+	Kerjasatu           float64 `json:"kerjasatu"`
+	Kerjadua            float64 `json:"kerjadua"`
+	Kerjatiga           float64 `json:"kerjatiga"`
+	Kerjaempat          float64 `json:"kerjaempat"`
+	Kerjalima           float64 `json:"kerjalima"`
+	Kerjaenam           float64 `json:"kerjaenam"`
+	Kerjatujuh          float64 `json:"kerjatujuh"`
+	Kerjadelapan        float64 `json:"kerjadelapan"`
+	Kerjasembilan       float64 `json:"kerjasembilan"`
+	Kerjasepuluh        float64 `json:"kerjasepuluh"`
+	Kerjasebelas        float64 `json:"kerjasebelas"`
+	Kerjaduabelas       float64 `json:"kerjaduabelas"`
+	Kerjatigabelas      float64 `json:"kerjatigabelas"`
+	Kerjaempatbelas     float64 `json:"kerjaempatbelas"`
+	Kerjalimabelas      float64 `json:"kerjalimabelas"`
+	Pekerjaansatu       float64 `json:"pekerjaansatu"`
+	Pekerjaandua        float64 `json:"pekerjaandua"`
+	Pekerjaantiga       float64 `json:"pekerjaantiga"`
+	Pekerjaanempat      float64 `json:"pekerjaanempat"`
+	Pekerjaanlima       float64 `json:"pekerjaanlima"`
+	Pekerjaanenam       float64 `json:"pekerjaanenam"`
+	Pekerjaantujuh      float64 `json:"pekerjaantujuh"`
+	Pekerjaandelapan    float64 `json:"pekerjaandelapan"`
+	Pekerjaansembilan   float64 `json:"pekerjaansembilan"`
+	Pekerjaansepuluh    float64 `json:"pekerjaansepuluh"`
+	Pekerjaansebelas    float64 `json:"pekerjaansebelas"`
+	Pekerjaanduabelas   float64 `json:"pekerjaanduabelas"`
+	Pekerjaantigabelas  float64 `json:"pekerjaantigabelas"`
+	Pekerjaanempatbelas float64 `json:"pekerjaanempatbelas"`
+	Pekerjaanlimabelas  float64 `json:"pekerjaanlimabelas"`
+
+	// --- Last Times ---
+	Lastadventure float64 `json:"lastadventure"`
+	Lastwar       float64 `json:"lastwar"`
+	Lastberkebon  float64 `json:"lastberkebon"`
+	Lastberburu   float64 `json:"lastberburu"`
+	Lastbansos    float64 `json:"lastbansos"`
+	Lastrampok    float64 `json:"lastrampok"`
+	Lastkill      float64 `json:"lastkill"`
+	Lastfishing   float64 `json:"lastfishing"`
+	Lastdungeon   float64 `json:"lastdungeon"`
+	Lastduel      float64 `json:"lastduel"`
+	Lastmining    float64 `json:"lastmining"`
+	Lasthourly    float64 `json:"lasthourly"`
+	Lastdagang    float64 `json:"lastdagang"`
+	Lasthunt      float64 `json:"lasthunt"`
+	Lasthun       float64 `json:"lasthun"`
+	Lastmonthly   float64 `json:"lastmonthly"`
+	Lastyearly    float64 `json:"lastyearly"`
+	Lastjb        float64 `json:"lastjb"`
+	Lastrob       float64 `json:"lastrob"`
+	Lastdaang     float64 `json:"lastdaang"`
+	Lastngojek    float64 `json:"lastngojek"`
+	Lastgrab      float64 `json:"lastgrab"`
+	Lastngocok    float64 `json:"lastngocok"`
+	Lastturu      float64 `json:"lastturu"`
+	Lastseen      float64 `json:"lastseen"`
+	LastSetStatus float64 `json:"lastSetStatus"`
+
+	// --- Status ---
+	PremiumDate float64 `json:"premiumDate"`
+	PremiumTime float64 `json:"premiumTime"`
+	Vip         string  `json:"vip"`
+	VipPoin     float64 `json:"vipPoin"`
+	Job         string  `json:"job"`
+	Jobexp      float64 `json:"jobexp"`
+	Penjara     bool    `json:"penjara"`
+	Antarpaket  float64 `json:"antarpaket"`
+	Dirawat     bool    `json:"dirawat"`
+	Lbars       string  `json:"lbars"`
+	Skill       string  `json:"skill"`
+	Korps       string  `json:"korps"`
+	Korpsgrade  string  `json:"korpsgrade"`
+
+	// --- Demon Slayer Stats ---
+	Demon          string  `json:"demon"`
+	Breaths        string  `json:"breaths"`
+	Magic          string  `json:"magic"`
+	Darahiblis     float64 `json:"darahiblis"`
+	Demonblood     float64 `json:"demonblood"`
+	Demonkill      float64 `json:"demonkill"`
+	Hashirakill    float64 `json:"hashirakill"`
+	Alldemonkill   float64 `json:"alldemonkill"`
+	Allhashirakill float64 `json:"allhashirakill"`
+	Attack         float64 `json:"attack"`
+	Speed          float64 `json:"speed"`
+	Strenght       float64 `json:"strenght"`
+	Defense        float64 `json:"defense"`
+	Regeneration   float64 `json:"regeneration"`
+
+	// --- E-Wallet & Farming ---
+	Ovo           float64 `json:"ovo"`
+	Dana          float64 `json:"dana"`
+	Gopay         float64 `json:"gopay"`
+	Lastngaji     float64 `json:"lastngaji"`
+	Lastlonte     float64 `json:"lastlonte"`
+	Lastkoboy     float64 `json:"lastkoboy"`
+	Lastdate      float64 `json:"lastdate"`
+	Lasttambang   float64 `json:"lasttambang"`
+	Lastngepet    float64 `json:"lastngepet"`
+	Mangga        float64 `json:"mangga"`
+	Stroberi      float64 `json:"stroberi"`
+	Semangka      float64 `json:"semangka"`
+	Jeruk         float64 `json:"jeruk"`
+	Pisang        float64 `json:"pisang"`
+	Bibitanggur   float64 `json:"bibitanggur"`
+	Bibitpisang   float64 `json:"bibitpisang"`
+	Bibitapel     float64 `json:"bibitapel"`
+	Bibitmangga   float64 `json:"bibitmangga"`
+	Bibitjeruk    float64 `json:"bibitjeruk"`
+	Healthmonster float64 `json:"healthmonster"`
+	Kingdom       bool    `json:"kingdom"`
+	Lastsda       float64 `json:"lastsda"`
+	Lastberbru    float64 `json:"lastberbru"`
+	Lastgift      float64 `json:"lastgift"`
+	Lastcodereg   float64 `json:"lastcodereg"`
+	Jualan        float64 `json:"jualan"`
+	Lastjualan    float64 `json:"lastjualan"`
+	Ngocokk       float64 `json:"ngocokk"`
+	Lastngocokk   float64 `json:"lastngocokk"`
+	Anggur        float64 `json:"anggur"`
+
+	// Key yang tidak dikenal
+	Extra map[string]interface{} `json:"-"`
+
+	// Key default yang tidak ada di JSON saat decode (lihat MissingFields)
+	missing []string
 }
 
 // mapping data user
@@ -59,6 +480,11 @@ func GetDefaultUserMap() map[string]interface{} {
 		"lastjobkerja":  0.0,
 		"lastjobchange": 0.0,
 
+		// --- Daily Streak ---
+		"dailyStreak":     0.0,
+		"bestDailyStreak": 0.0,
+		"streakfreeze":    0.0,
+
 		// --- Nested Objects (PENTING) ---
 		"rpg": map[string]interface{}{
 			"level":     1.0,
@@ -98,7 +524,6 @@ func GetDefaultUserMap() map[string]interface{} {
 		"laper":         100.0,
 		"tprem":         0.0,
 		"stamina":       100.0,
-		"Health":        100.0,
 		"follow":        0.0,
 		"lastfollow":    0.0,
 		"followers":     0.0,
@@ -0,0 +1,247 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Encode/decode User (dan object nested-nya) dengan tetap menyimpan key yang
+// tidak dikenal di Extra, supaya tidak ada data yang hilang saat dokumen lama
+// dibaca lalu disimpan ulang.
+
+type userAlias User
+type rpgAlias RpgStats
+type jailAlias JailStats
+type motorAlias MotorStats
+
+var (
+	userKeys  = jsonKeys(reflect.TypeOf(userAlias{}))
+	rpgKeys   = jsonKeys(reflect.TypeOf(rpgAlias{}))
+	jailKeys  = jsonKeys(reflect.TypeOf(jailAlias{}))
+	motorKeys = jsonKeys(reflect.TypeOf(motorAlias{}))
+
+	defaultUserJSON, _ = json.Marshal(GetDefaultUserMap())
+)
+
+// NewUser membuat user dengan semua nilai default dari GetDefaultUserMap
+func NewUser() *User {
+	u := &User{}
+	json.Unmarshal(defaultUserJSON, u)
+	u.clearMissing()
+	return u
+}
+
+// UserFromMap membuat User dari dokumen map. Key yang tidak ada diisi nilai
+// default. Error *TypeMismatchError berarti ada field yang tipenya salah
+// (nilainya disimpan apa adanya di Extra), User tetap dikembalikan.
+func UserFromMap(m map[string]interface{}) (*User, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	u := NewUser()
+	err = json.Unmarshal(b, u)
+	return u, err
+}
+
+// ToMap mengubah User menjadi dokumen map (bentuk yang tersimpan di DB)
+func (u *User) ToMap() map[string]interface{} {
+	b, _ := json.Marshal(u)
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	return m
+}
+
+// MissingFields adalah key default (termasuk nested, misal "rpg.level") yang
+// tidak ada di JSON terakhir yang di-decode ke User ini, jadi nilainya berasal
+// dari default. Dipakai untuk tahu apakah dokumen perlu disimpan ulang.
+func (u *User) MissingFields() []string {
+	missing := append([]string{}, u.missing...)
+	for _, nested := range []struct {
+		prefix  string
+		missing []string
+	}{{"rpg", u.Rpg.missing}, {"jail", u.Jail.missing}, {"motor", u.Motor.missing}} {
+		for _, k := range nested.missing {
+			missing = append(missing, nested.prefix+"."+k)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (u *User) clearMissing() {
+	u.missing = nil
+	u.Rpg.missing = nil
+	u.Jail.missing = nil
+	u.Motor.missing = nil
+}
+
+// TypeMismatchError menandakan field yang nilainya tidak cocok dengan tipe
+// field-nya. Nilai tersebut tidak dibuang: disimpan apa adanya di Extra dan
+// ditulis ulang tanpa diubah oleh MarshalJSON. Fields berupa dotted path.
+type TypeMismatchError struct {
+	Fields []string
+	Err    error // error decode pertama, biasanya *json.UnmarshalTypeError
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("field %s tidak sesuai tipe (disimpan apa adanya): %v", strings.Join(e.Fields, ", "), e.Err)
+}
+
+func (e *TypeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+func (u User) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(userAlias(u), u.Extra)
+}
+
+func (u *User) UnmarshalJSON(b []byte) error {
+	extra, missing, err := unmarshalWithExtra(b, (*userAlias)(u), userKeys)
+	u.Extra = extra
+	u.missing = missing
+	return err
+}
+
+func (r RpgStats) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(rpgAlias(r), r.Extra)
+}
+
+func (r *RpgStats) UnmarshalJSON(b []byte) error {
+	extra, missing, err := unmarshalWithExtra(b, (*rpgAlias)(r), rpgKeys)
+	r.Extra = extra
+	r.missing = missing
+	return err
+}
+
+func (j JailStats) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(jailAlias(j), j.Extra)
+}
+
+func (j *JailStats) UnmarshalJSON(b []byte) error {
+	extra, missing, err := unmarshalWithExtra(b, (*jailAlias)(j), jailKeys)
+	j.Extra = extra
+	j.missing = missing
+	return err
+}
+
+func (m MotorStats) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(motorAlias(m), m.Extra)
+}
+
+func (m *MotorStats) UnmarshalJSON(b []byte) error {
+	extra, missing, err := unmarshalWithExtra(b, (*motorAlias)(m), motorKeys)
+	m.Extra = extra
+	m.missing = missing
+	return err
+}
+
+// jsonKeys mengumpulkan nama key JSON dari struct. Nilainya true kalau key
+// wajib ada (tanpa omitempty), dipakai untuk MissingFields.
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		keys[name] = !strings.Contains(opts, "omitempty")
+	}
+	return keys
+}
+
+// marshalWithExtra meng-encode v lalu menambahkan key dari extra. Key yang
+// dikenal hanya ada di extra kalau nilai tersimpannya tidak cocok tipe (lihat
+// TypeMismatchError), jadi nilai extra itulah yang ditulis.
+func marshalWithExtra(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for k, val := range extra {
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		fields[k] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// unmarshalWithExtra meng-decode key yang dikenal ke v (nilai lama v dipakai
+// untuk key yang tidak ada), mengembalikan key tak dikenal dan daftar key
+// wajib yang tidak ada di JSON. Key dikenal yang tipenya tidak cocok ikut
+// masuk extra apa adanya dan dilaporkan lewat *TypeMismatchError.
+func unmarshalWithExtra(b []byte, v interface{}, known map[string]bool) (map[string]interface{}, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	knownRaw := make(map[string]json.RawMessage, len(raw))
+	var extra map[string]interface{}
+	for k, val := range raw {
+		if _, ok := known[k]; ok {
+			knownRaw[k] = val
+			continue
+		}
+		var decoded interface{}
+		json.Unmarshal(val, &decoded)
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[k] = decoded
+	}
+
+	var missing []string
+	for k, required := range known {
+		if _, ok := raw[k]; required && !ok {
+			missing = append(missing, k)
+		}
+	}
+
+	knownJSON, _ := json.Marshal(knownRaw)
+	if err := json.Unmarshal(knownJSON, v); err == nil {
+		return extra, missing, nil
+	}
+
+	// Ada yang gagal: decode ulang per key supaya tahu key mana yang salah
+	mismatch := &TypeMismatchError{}
+	for k, val := range knownRaw {
+		one, _ := json.Marshal(map[string]json.RawMessage{k: val})
+		err := json.Unmarshal(one, v)
+		if err == nil {
+			continue
+		}
+		if mismatch.Err == nil {
+			mismatch.Err = err
+		}
+		var nested *TypeMismatchError
+		if errors.As(err, &nested) {
+			// object nested sudah menyimpan field salahnya di Extra sendiri
+			for _, f := range nested.Fields {
+				mismatch.Fields = append(mismatch.Fields, k+"."+f)
+			}
+			continue
+		}
+		var decoded interface{}
+		json.Unmarshal(val, &decoded)
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[k] = decoded
+		mismatch.Fields = append(mismatch.Fields, k)
+	}
+	sort.Strings(mismatch.Fields)
+	return extra, missing, mismatch
+}
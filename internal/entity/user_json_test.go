@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUserFromMap(t *testing.T) {
+	tests := []struct {
+		name         string
+		doc          map[string]interface{}
+		wantMismatch []string
+		check        func(t *testing.T, u *User, m map[string]interface{})
+	}{
+		{
+			name: "key hilang diisi default",
+			doc:  map[string]interface{}{"money": 5.0, "rpg": map[string]interface{}{"level": 3.0}},
+			check: func(t *testing.T, u *User, m map[string]interface{}) {
+				if u.Money != 5 || u.Rpg.Level != 3 || u.Rpg.Health != 100 {
+					t.Errorf("money=%v level=%v health=%v", u.Money, u.Rpg.Level, u.Rpg.Health)
+				}
+			},
+		},
+		{
+			name: "key asing tetap ada",
+			doc:  map[string]interface{}{"custom": "x", "rpg": map[string]interface{}{"skill": 2.0}},
+			check: func(t *testing.T, u *User, m map[string]interface{}) {
+				if m["custom"] != "x" || m["rpg"].(map[string]interface{})["skill"] != 2.0 {
+					t.Errorf("extra hilang: %v %v", m["custom"], m["rpg"])
+				}
+			},
+		},
+		{
+			name:         "tipe salah disimpan apa adanya",
+			doc:          map[string]interface{}{"money": "banyak", "diamond": 4.0},
+			wantMismatch: []string{"money"},
+			check: func(t *testing.T, u *User, m map[string]interface{}) {
+				if m["money"] != "banyak" || u.Diamond != 4 {
+					t.Errorf("money=%v diamond=%v", m["money"], u.Diamond)
+				}
+			},
+		},
+		{
+			name:         "tipe salah nested",
+			doc:          map[string]interface{}{"rpg": map[string]interface{}{"level": "x", "exp": 7.0}},
+			wantMismatch: []string{"rpg.level"},
+			check: func(t *testing.T, u *User, m map[string]interface{}) {
+				rpg := m["rpg"].(map[string]interface{})
+				if rpg["level"] != "x" || u.Rpg.Exp != 7 {
+					t.Errorf("rpg=%v", rpg)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := UserFromMap(tt.doc)
+			var mismatch *TypeMismatchError
+			switch {
+			case tt.wantMismatch == nil && err != nil:
+				t.Fatalf("err = %v", err)
+			case tt.wantMismatch != nil && !errors.As(err, &mismatch):
+				t.Fatalf("err = %v, mau *TypeMismatchError", err)
+			case tt.wantMismatch != nil && !reflect.DeepEqual(mismatch.Fields, tt.wantMismatch):
+				t.Fatalf("fields = %v, mau %v", mismatch.Fields, tt.wantMismatch)
+			}
+			tt.check(t, u, u.ToMap())
+		})
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	if missing := NewUser().MissingFields(); len(missing) != 0 {
+		t.Errorf("NewUser missing = %v", missing)
+	}
+	doc := GetDefaultUserMap()
+	delete(doc, "money")
+	delete(doc["rpg"].(map[string]interface{}), "mana")
+	u, err := UserFromMap(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.MissingFields(), []string{"money", "rpg.mana"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingFields = %v, mau %v", got, want)
+	}
+}
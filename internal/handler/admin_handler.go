@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"Berpg/internal/repository"
+	"Berpg/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler untuk endpoint moderator (/admin/*)
+type AdminHandler struct {
+	Service *service.UserService
+
+	// WriteBehind nil kalau mode write-behind tidak aktif
+	WriteBehind *repository.WriteBehindRepository
+
+	// SnapshotRetentionDays umur snapshot yang disimpan, 0 = selamanya
+	SnapshotRetentionDays int
+}
+
+func NewAdminHandler(s *service.UserService) *AdminHandler {
+	return &AdminHandler{Service: s}
+}
+
+// GET /admin/audit?userId=&since=&limit=
+func (h *AdminHandler) GetAuditLog(c echo.Context) error {
+	userID := c.QueryParam("userId")
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	var since int64
+	if raw := c.QueryParam("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status": false, "message": "Parameter 'since' harus timestamp milidetik.",
+			})
+		}
+		since = parsed
+	}
+
+	entries, err := h.Service.GetAuditLog(c.Request().Context(), userID, since, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil audit log",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data":   entries,
+	})
+}
+
+// POST /admin/user/:userId/restore?at=<timestamp ms>&dryRun=true
+func (h *AdminHandler) RestoreUser(c echo.Context) error {
+	userID := c.Param("userId")
+	at, err := strconv.ParseInt(c.QueryParam("at"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Parameter 'at' wajib diisi timestamp milidetik.",
+		})
+	}
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+
+	result, err := h.Service.RestoreUser(c.Request().Context(), userID, at, dryRun)
+	if errors.Is(err, service.ErrSnapshotNotFound) || errors.Is(err, service.ErrUserNotFound) {
+		message := "Tidak ada snapshot user " + userID + " sebelum waktu tersebut."
+		if h.SnapshotRetentionDays > 0 {
+			message += " Snapshot hanya disimpan " + strconv.Itoa(h.SnapshotRetentionDays) + " hari (SNAPSHOT_RETENTION_DAYS)."
+		}
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal restore data user",
+		})
+	}
+
+	message := "Data user " + userID + " berhasil di-restore."
+	if dryRun {
+		message = "Dry-run: belum ada data yang diubah."
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  true,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// POST /admin/backfill (mulai atau lanjutkan pengisian field default ke semua user)
+func (h *AdminHandler) StartBackfill(c echo.Context) error {
+	job, err := h.Service.StartBackfill(c.Request().Context())
+	if errors.Is(err, service.ErrBackfillRunning) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"status": false, "message": "Backfill masih berjalan, cek progress di GET /admin/backfill.",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal memulai backfill",
+		})
+	}
+
+	message := "Backfill dimulai."
+	if job.Processed > 0 {
+		message = "Backfill dilanjutkan dari user " + job.Cursor + "."
+	}
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"status":  true,
+		"message": message,
+		"data":    backfillProgress(job),
+	})
+}
+
+// GET /admin/backfill
+func (h *AdminHandler) GetBackfill(c echo.Context) error {
+	job, err := h.Service.GetBackfillProgress(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil progress backfill",
+		})
+	}
+	if job == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "Belum pernah ada backfill.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data":   backfillProgress(job),
+	})
+}
+
+func backfillProgress(job *repository.BackfillJob) map[string]interface{} {
+	percent := 100.0
+	if job.Total > 0 && job.Status != repository.BackfillDone {
+		percent = float64(job.Processed) * 100 / float64(job.Total)
+	}
+	return map[string]interface{}{
+		"job":     job,
+		"percent": percent,
+	}
+}
+
+// GET /admin/write-behind (antrian penulisan yang belum tersimpan dan flush lag)
+func (h *AdminHandler) GetWriteBehind(c echo.Context) error {
+	if h.WriteBehind == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status": true,
+			"data":   map[string]interface{}{"enabled": false},
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data": map[string]interface{}{
+			"enabled":     true,
+			"intervalMs":  h.WriteBehind.Interval.Milliseconds(),
+			"maxBatch":    h.WriteBehind.MaxBatch,
+			"stats":       h.WriteBehind.Stats(),
+			"deadLetters": h.WriteBehind.DeadLetters(),
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"Berpg/internal/repository"
+	"Berpg/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SeasonHandler untuk leaderboard season (/leaderboard/seasons, /admin/seasons)
+type SeasonHandler struct {
+	Service *service.SeasonService
+}
+
+func NewSeasonHandler(s *service.SeasonService) *SeasonHandler {
+	return &SeasonHandler{Service: s}
+}
+
+// GET /leaderboard/seasons
+func (h *SeasonHandler) ListSeasons(c echo.Context) error {
+	seasons, err := h.Service.ListSeasons(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil daftar season",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data":   seasons,
+	})
+}
+
+// GET /leaderboard/seasons/:id?type=money&kind=gained&limit=10&offset=0
+// (:id boleh "current" untuk season yang sedang berjalan)
+func (h *SeasonHandler) GetSeason(c echo.Context) error {
+	id := c.Param("id")
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	data, err := h.Service.GetSeasonLeaderboard(c.Request().Context(), id, c.QueryParam("type"), c.QueryParam("kind"), limit, offset)
+	switch {
+	case errors.Is(err, service.ErrSeasonNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "Season " + id + " tidak ditemukan.",
+		})
+	case errors.Is(err, service.ErrInvalidSeason):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil leaderboard season",
+		})
+	}
+
+	data["status"] = true
+	return c.JSON(http.StatusOK, data)
+}
+
+// POST /admin/seasons {"name", "startsAt", "endsAt", "types", "topN"}
+// (waktu dalam timestamp milidetik)
+func (h *SeasonHandler) CreateSeason(c echo.Context) error {
+	var season repository.Season
+	if err := c.Bind(&season); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Body harus JSON season.",
+		})
+	}
+	season.Name = strings.TrimSpace(season.Name)
+
+	err := h.Service.CreateSeason(c.Request().Context(), &season)
+	if errors.Is(err, service.ErrInvalidSeason) {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal membuat season",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  true,
+		"message": "Season " + season.Name + " dibuat.",
+		"data":    season,
+	})
+}
@@ -3,39 +3,97 @@ package handler
 import (
 	"Berpg/internal/repository"
 	"Berpg/internal/service"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 type UserHandler struct {
 	Service   *service.UserService
-	StatsRepo *repository.StatsRepository
+	StatsRepo repository.StatsStore
 }
 
-func NewUserHandler(s *service.UserService, stats *repository.StatsRepository) *UserHandler {
+func NewUserHandler(s *service.UserService, stats repository.StatsStore) *UserHandler {
 	return &UserHandler{
 		Service:   s,
 		StatsRepo: stats,
 	}
 }
 
+// ETag dokumen user = versinya
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatchVersion membaca header If-Match. Tanpa header (atau "*") berarti
+// repository.AnyVersion. Versi dokumen mulai dari 1, jadi nilai < 1 ditolak.
+func ifMatchVersion(c echo.Context) (int64, error) {
+	raw := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return repository.AnyVersion, nil
+	}
+	raw = strings.TrimPrefix(raw, "W/")
+	raw = strings.Trim(raw, `"`)
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 {
+		return 0, fmt.Errorf("versi If-Match harus >= 1, dapat %d", version)
+	}
+	return version, nil
+}
+
+// guardHeader membaca guard dari header X-Guard untuk POST/PATCH
+// /user/:userId (body di sana adalah dokumen user). Header boleh diulang
+// atau dipisah koma, misal "X-Guard: money >= 5000, jail.status == false".
+func guardHeader(c echo.Context) []string {
+	var guards []string
+	for _, value := range c.Request().Header.Values("X-Guard") {
+		for _, expr := range strings.Split(value, ",") {
+			if expr = strings.TrimSpace(expr); expr != "" {
+				guards = append(guards, expr)
+			}
+		}
+	}
+	return guards
+}
+
+func versionConflict(c echo.Context, userID string) error {
+	return c.JSON(http.StatusConflict, map[string]interface{}{
+		"status":  false,
+		"message": "Data user " + userID + " sudah diubah oleh proses lain. Ambil ulang data lalu coba lagi.",
+	})
+}
+
+func invalidIfMatch(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"status": false, "message": "Header If-Match tidak valid (contoh: \"3\").",
+	})
+}
+
 // GET /user/:userId
 func (h *UserHandler) GetUser(c echo.Context) error {
 	userID := c.Param("userId")
 	username := c.QueryParam("username")
 
-	user, err := h.Service.GetOrInitUser(c.Request().Context(), userID, username)
+	user, version, err := h.Service.GetOrInitUser(c.Request().Context(), userID, username)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status": false, "message": "Terjadi kesalahan internal.",
 		})
 	}
 
+	setETag(c, version)
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"status":  true,
 		"message": "Data untuk user " + userID + " berhasil diambil.",
+		"version": version,
 		"data":    user,
 	})
 }
@@ -45,6 +103,16 @@ func (h *UserHandler) ClaimDaily(c echo.Context) error {
 	userID := c.Param("userId")
 
 	result, err := h.Service.ClaimDaily(c.Request().Context(), userID)
+	var cooldownErr *service.CooldownError
+	if errors.As(err, &cooldownErr) {
+		// Tetap 400 (response lama POST /daily), ditambah kapan bisa klaim lagi
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":      false,
+			"message":     err.Error(),
+			"remainingMs": cooldownErr.Remaining.Milliseconds(),
+			"nextClaimAt": cooldownErr.ReadyAt,
+		})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"status":  false,
@@ -54,30 +122,462 @@ func (h *UserHandler) ClaimDaily(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
-// POST /user/:userId
+// POST /claim/:period/:userId (periode dari file config klaim, lihat service.ClaimPeriod)
+func (h *UserHandler) Claim(c echo.Context) error {
+	userID := c.Param("userId")
+	period := c.Param("period")
+
+	result, err := h.Service.Claim(c.Request().Context(), userID, period)
+	var cooldownErr *service.CooldownError
+	switch {
+	case errors.As(err, &cooldownErr):
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"status":      false,
+			"message":     err.Error(),
+			"period":      period,
+			"remainingMs": cooldownErr.Remaining.Milliseconds(),
+			"readyAt":     cooldownErr.ReadyAt,
+			"nextClaimAt": cooldownErr.ReadyAt,
+		})
+	case errors.Is(err, service.ErrUnknownClaim):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status":  false,
+			"message": fmt.Sprintf("Periode klaim '%s' tidak dikenal (pilihan: %s).", period, strings.Join(h.Service.ClaimPeriods(), ", ")),
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	resp := map[string]interface{}{
+		"status":      true,
+		"message":     result.Message(),
+		"period":      period,
+		"rewards":     result.Rewards,
+		"values":      result.Values(),
+		"claimedAt":   result.ClaimedAt,
+		"nextClaimAt": result.NextClaimAt,
+	}
+	if result.Streak != nil {
+		resp["streak"] = result.Streak
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GET /streak/:userId
+func (h *UserHandler) GetStreak(c echo.Context) error {
+	userID := c.Param("userId")
+
+	data, err := h.Service.GetStreak(c.Request().Context(), userID)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	case errors.Is(err, service.ErrUnknownClaim):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "Klaim daily tidak aktif di config klaim.",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil streak",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data":   data,
+	})
+}
+
+// POST /streak/freeze/:userId (beli 1 streakfreeze seharga service.StreakFreezePrice diamond)
+func (h *UserHandler) BuyStreakFreeze(c echo.Context) error {
+	userID := c.Param("userId")
+
+	user, err := h.Service.BuyStreakFreeze(c.Request().Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":       true,
+		"message":      fmt.Sprintf("Berhasil beli streak freeze seharga %d Diamond.", service.StreakFreezePrice),
+		"streakFreeze": user.StreakFreeze,
+		"diamond":      user.Diamond,
+	})
+}
+
+// POST /user/:userId/cooldown/:action (cek + cap cooldown aksi, lihat service.CooldownRegistry)
+func (h *UserHandler) UseCooldown(c echo.Context) error {
+	userID := c.Param("userId")
+	action := c.Param("action")
+
+	st, err := h.Service.UseCooldown(c.Request().Context(), userID, action)
+	var cooldownErr *service.CooldownError
+	switch {
+	case errors.As(err, &cooldownErr):
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"status":      false,
+			"message":     err.Error(),
+			"action":      action,
+			"remainingMs": cooldownErr.Remaining.Milliseconds(),
+			"readyAt":     cooldownErr.ReadyAt,
+		})
+	case errors.Is(err, service.ErrUnknownCooldown):
+		message := "Aksi '" + action + "' tidak punya cooldown."
+		if _, ok := h.Service.Claims[action]; ok {
+			message = "Aksi '" + action + "' adalah klaim hadiah, pakai endpoint klaim."
+		}
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": message,
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  true,
+		"message": "Cooldown " + action + " dimulai.",
+		"data":    st,
+	})
+}
+
+// GET /user/:userId/cooldowns
+func (h *UserHandler) GetCooldowns(c echo.Context) error {
+	userID := c.Param("userId")
+
+	data, err := h.Service.GetCooldowns(c.Request().Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil cooldown",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": true,
+		"data":   data,
+	})
+}
+
+// POST /user/:userId (guard opsional lewat header X-Guard, lihat guardHeader)
 func (h *UserHandler) UpdateUser(c echo.Context) error {
 	userID := c.Param("userId")
 	var body map[string]interface{}
 
-	if err := c.Bind(&body); err != nil || len(body) == 0 {
+	// BindBody saja, supaya path param (userId) tidak ikut tersimpan di dokumen
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, &body); err != nil || len(body) == 0 {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"status": false, "message": "Body request tidak boleh kosong.",
 		})
 	}
 
-	err := h.Service.UpdateUser(c.Request().Context(), userID, body)
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c)
+	}
+
+	version, err := h.Service.UpdateUser(c.Request().Context(), userID, body, expectedVersion, guardHeader(c))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return versionConflict(c, userID)
+	}
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status": false, "message": "Terjadi kesalahan internal.",
 		})
 	}
 
+	setETag(c, version)
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"status": true, "message": "Data untuk user " + userID + " berhasil diperbarui.",
+		"version": version,
+	})
+}
+
+// PATCH /user/:userId (JSON Merge Patch, RFC 7396; guard opsional lewat header X-Guard)
+func (h *UserHandler) PatchUser(c echo.Context) error {
+	userID := c.Param("userId")
+	var patch map[string]interface{}
+
+	// BindBody saja, supaya path param (userId) tidak ikut masuk ke patch
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, &patch); err != nil || len(patch) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Body patch harus berupa object JSON dan tidak boleh kosong.",
+		})
+	}
+
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c)
+	}
+
+	user, version, err := h.Service.PatchUser(c.Request().Context(), userID, patch, expectedVersion, guardHeader(c))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return versionConflict(c, userID)
+	}
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  true,
+		"message": "Data untuk user " + userID + " berhasil di-patch.",
+		"version": version,
+		"data":    user,
+	})
+}
+
+// POST /user/:userId/ops (operasi atomik, misal {"ops":[{"op":"dec","path":"money","value":5000}],"guards":["money >= 5000"]})
+func (h *UserHandler) ApplyOps(c echo.Context) error {
+	userID := c.Param("userId")
+	var body struct {
+		Ops    []service.UserOp `json:"ops"`
+		Guards []string         `json:"guards"`
+		Reason string           `json:"reason"` // dicatat di ledger, misal "buy:pancing"
+	}
+
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, &body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Body harus berupa {\"ops\": [...]}.",
+		})
+	}
+
+	user, version, err := h.Service.ApplyOps(contextWithReason(c, body.Reason), userID, body.Ops, body.Guards)
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  true,
+		"message": "Operasi untuk user " + userID + " berhasil dijalankan.",
+		"version": version,
+		"data":    user,
+	})
+}
+
+// POST /transfer (body: {"from":"a","to":"b","field":"money","amount":500,"note":"..."})
+func (h *UserHandler) Transfer(c echo.Context) error {
+	var body repository.Transfer
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, &body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Body transfer tidak valid.",
+		})
+	}
+
+	result, err := h.Service.Transfer(c.Request().Context(), body)
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidTransfer):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// POST /tx (body: {"users": {"a": {"ops": [...], "guards": [...]}, "b": {...}}})
+func (h *UserHandler) ApplyTx(c echo.Context) error {
+	var body struct {
+		Users  map[string]service.UserTxOps `json:"users"`
+		Reason string                       `json:"reason"`
+	}
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, &body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Body harus berupa {\"users\": {\"<userId>\": {\"ops\": [...]}}}.",
+		})
+	}
+
+	users, err := h.Service.ApplyTx(contextWithReason(c, body.Reason), body.Users)
+	if handled, respErr := writeOpsError(c, err); handled {
+		return respErr
+	}
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  true,
+		"message": "Transaksi berhasil dijalankan.",
+		"data":    users,
 	})
 }
 
-// GET /leaderboard
+// contextWithReason menambahkan alasan penulisan dari body (kalau ada)
+func contextWithReason(c echo.Context, reason string) context.Context {
+	ctx := c.Request().Context()
+	if reason != "" {
+		ctx = repository.WithReason(ctx, reason)
+	}
+	return ctx
+}
+
+// GET /user/:userId/history?field=money&limit=20&cursor=
+func (h *UserHandler) GetHistory(c echo.Context) error {
+	userID := c.Param("userId")
+	field := c.QueryParam("field")
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	var cursor int64
+	if raw := c.QueryParam("cursor"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status": false, "message": "Parameter 'cursor' tidak valid.",
+			})
+		}
+		cursor = parsed
+	}
+
+	entries, nextCursor, err := h.Service.GetHistory(c.Request().Context(), userID, field, limit, cursor)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Gagal mengambil riwayat transaksi",
+		})
+	}
+
+	resp := map[string]interface{}{
+		"status":     true,
+		"data":       entries,
+		"nextCursor": nil,
+	}
+	if nextCursor > 0 {
+		resp["nextCursor"] = nextCursor
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// writeOpsError menulis response untuk error operasi / guard / schema.
+// handled false (response belum ditulis) kalau err bukan salah satunya.
+func writeOpsError(c echo.Context, err error) (bool, error) {
+	var opErr *service.OpError
+	var syntaxErr *service.GuardSyntaxError
+	var guardErr *service.GuardError
+	var validationErr *service.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		resp := map[string]interface{}{
+			"status":  false,
+			"message": validationErr.Error(),
+			"errors":  validationErr.Errors,
+		}
+		if validationErr.UserID != "" {
+			resp["userId"] = validationErr.UserID
+		}
+		return true, c.JSON(http.StatusUnprocessableEntity, resp)
+	case errors.As(err, &opErr):
+		return true, c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": opErr.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidDocument):
+		return true, c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": err.Error(),
+		})
+	case errors.As(err, &syntaxErr):
+		resp := map[string]interface{}{
+			"status": false, "message": syntaxErr.Error(),
+		}
+		if syntaxErr.UserID != "" {
+			resp["userId"] = syntaxErr.UserID
+		}
+		return true, c.JSON(http.StatusBadRequest, resp)
+	case errors.As(err, &guardErr):
+		resp := map[string]interface{}{
+			"status":      false,
+			"message":     guardErr.Error(),
+			"failedGuard": guardErr.Guard,
+			"actual":      guardErr.Actual,
+		}
+		if guardErr.UserID != "" {
+			resp["userId"] = guardErr.UserID
+		}
+		return true, c.JSON(http.StatusPreconditionFailed, resp)
+	}
+	return false, nil
+}
+
+// GET /leaderboard?type=money&order=desc&limit=10&offset=0 atau &cursor=...
 func (h *UserHandler) GetLeaderboard(c echo.Context) error {
 	lbType := c.QueryParam("type")
 	limitStr := c.QueryParam("limit")
@@ -87,25 +587,108 @@ func (h *UserHandler) GetLeaderboard(c echo.Context) error {
 
 	if lbType == "" {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"status": false, "message": "Parameter 'type' diperlukan (pilihan: money, level, wealth).",
+			"status": false, "message": "Parameter 'type' diperlukan (contoh: money, level, wealth, rpg.exp, subscribers).",
+		})
+	}
+
+	order := c.QueryParam("order")
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Parameter 'order' harus asc atau desc.",
+		})
+	}
+
+	var offset int
+	if raw := c.QueryParam("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status": false, "message": "Parameter 'offset' tidak valid.",
+			})
+		}
+		offset = parsed
+	}
+	cursor := c.QueryParam("cursor")
+	if cursor != "" && offset > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Pakai salah satu: 'offset' atau 'cursor'.",
 		})
 	}
 
 	limit, _ := strconv.Atoi(limitStr)
-	data, totalUsers, err := h.Service.GetLeaderboard(c.Request().Context(), lbType, limit)
+	data, totalUsers, nextCursor, err := h.Service.GetLeaderboard(c.Request().Context(), lbType, order == "asc", limit, offset, cursor)
 
-	if err != nil {
+	switch {
+	case errors.Is(err, service.ErrUnknownLeaderboard):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": fmt.Sprintf("Tipe leaderboard '%s' tidak dikenal, harus field angka user (contoh: money, level, wealth, rpg.exp, subscribers).", lbType),
+		})
+	case errors.Is(err, service.ErrInvalidCursor):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Parameter 'cursor' tidak valid.",
+		})
+	case err != nil:
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status": false, "message": "Terjadi kesalahan internal.",
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"totalUsers":       totalUsers,
 		"status":           true,
 		"leaderboard_type": lbType,
+		"order":            order,
 		"data":             data,
-	})
+		"nextCursor":       nil,
+	}
+	if nextCursor != "" {
+		resp["nextCursor"] = nextCursor
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GET /leaderboard/rank/:userId?type=money&order=desc
+func (h *UserHandler) GetLeaderboardRank(c echo.Context) error {
+	userID := c.Param("userId")
+	lbType := c.QueryParam("type")
+	if lbType == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Parameter 'type' diperlukan (contoh: money, level, wealth, rpg.exp, subscribers).",
+		})
+	}
+	order := c.QueryParam("order")
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": "Parameter 'order' harus asc atau desc.",
+		})
+	}
+
+	data, err := h.Service.GetLeaderboardRank(c.Request().Context(), lbType, order == "asc", userID)
+	switch {
+	case errors.Is(err, service.ErrUnknownLeaderboard):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status": false, "message": fmt.Sprintf("Tipe leaderboard '%s' tidak dikenal, harus field angka user (contoh: money, level, wealth, rpg.exp, subscribers).", lbType),
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status": false, "message": "User " + userID + " tidak ditemukan.",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status": false, "message": "Terjadi kesalahan internal.",
+		})
+	}
+
+	data["status"] = true
+	data["leaderboard_type"] = lbType
+	data["order"] = order
+	return c.JSON(http.StatusOK, data)
 }
 
 // GET /stats
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"Berpg/internal/repository"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"os"
 
@@ -26,13 +28,52 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
+			// Identitas pemanggil untuk audit log (bukan key aslinya)
+			setActor(c, "api:"+keyFingerprint(clientKey))
 			return next(c)
 		}
 	}
 }
 
+// Admin Middleware, untuk endpoint moderator (/admin/*). Dipasang setelah
+// AuthMiddleware dan butuh header x-admin-key yang cocok dengan ADMIN_API_KEY.
+func AdminMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			clientKey := c.Request().Header.Get("x-admin-key")
+			serverKey := os.Getenv("ADMIN_API_KEY")
+
+			if serverKey == "" {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Server misconfiguration: ADMIN_API_KEY not set"})
+			}
+
+			if clientKey != serverKey {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Akses Ditolak: khusus admin",
+				})
+			}
+
+			setActor(c, "admin:"+keyFingerprint(clientKey))
+			return next(c)
+		}
+	}
+}
+
+// keyFingerprint = 8 karakter awal sha256 dari key, cukup untuk membedakan
+// key tanpa menyimpan key aslinya
+func keyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func setActor(c echo.Context, actor string) {
+	req := c.Request()
+	c.SetRequest(req.WithContext(repository.WithActor(req.Context(), actor)))
+}
+
 // Traffic Logger Middleware
-func TrafficLogger(repo *repository.StatsRepository) echo.MiddlewareFunc {
+func TrafficLogger(repo repository.StatsStore) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if c.Path() == "/api/features/rpg/stats" || c.Path() == "/favicon.ico" {
@@ -44,3 +85,22 @@ func TrafficLogger(repo *repository.StatsRepository) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// RequestContext menaruh request ID (dari middleware RequestID echo) ke context
+// request supaya ikut tercatat di ledger
+func RequestContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+			}
+			if requestID != "" {
+				req := c.Request()
+				c.SetRequest(req.WithContext(repository.WithRequestID(req.Context(), requestID)))
+			}
+
+			return next(c)
+		}
+	}
+}
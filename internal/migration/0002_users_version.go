@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"database/sql"
+)
+
+// Kolom version untuk optimistic concurrency. Ditulis dalam Go karena DB yang
+// dibuat sebelum ada sistem migration mungkin sudah punya kolom ini.
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "users_version",
+		Up: func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error {
+			if dialect == repository.Postgres {
+				_, err := db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1")
+				return err
+			}
+			exists, err := columnExists(ctx, db, "users", "version")
+			if err != nil || exists {
+				return err
+			}
+			_, err = db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1")
+			return err
+		},
+		Down: func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error {
+			if dialect == repository.Postgres {
+				_, err := db.ExecContext(ctx, "ALTER TABLE users DROP COLUMN IF EXISTS version")
+				return err
+			}
+			exists, err := columnExists(ctx, db, "users", "version")
+			if err != nil || !exists {
+				return err
+			}
+			_, err = db.ExecContext(ctx, "ALTER TABLE users DROP COLUMN version")
+			return err
+		},
+	})
+}
+
+// columnExists khusus SQLite (pragma_table_info)
+func columnExists(ctx context.Context, q dbtx, table, column string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	return count > 0, err
+}
@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"database/sql"
+)
+
+// Dokumen lama punya dua health: "Health" di level atas dan "rpg.health".
+// Yang dipakai sekarang hanya rpg.health. Kalau rpg.health masih default
+// (atau tidak ada) sementara Health sudah berubah, nilai Health yang dipakai.
+const defaultHealth = 100.0
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "merge_health",
+		Up: func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error {
+			return transformUsers(ctx, db, dialect, "merge_health", func(doc map[string]interface{}) bool {
+				legacy, ok := doc["Health"]
+				if !ok {
+					return false
+				}
+				delete(doc, "Health")
+
+				rpg, isObj := doc["rpg"].(map[string]interface{})
+				if !isObj {
+					rpg = make(map[string]interface{})
+					doc["rpg"] = rpg
+				}
+				current, hasCurrent := rpg["health"].(float64)
+				if legacyNum, isNum := legacy.(float64); isNum && (!hasCurrent || current == defaultHealth) {
+					rpg["health"] = legacyNum
+				}
+				return true
+			})
+		},
+		Down: func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error {
+			return transformUsers(ctx, db, dialect, "merge_health_down", func(doc map[string]interface{}) bool {
+				if _, ok := doc["Health"]; ok {
+					return false
+				}
+				health := defaultHealth
+				if rpg, isObj := doc["rpg"].(map[string]interface{}); isObj {
+					if v, isNum := rpg["health"].(float64); isNum {
+						health = v
+					}
+				}
+				doc["Health"] = health
+				return true
+			})
+		},
+	})
+}
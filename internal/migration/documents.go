@@ -0,0 +1,147 @@
+package migration
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// jumlah dokumen user per transaksi pada migration dokumen
+const documentBatchSize = 500
+
+type userRow struct {
+	id   string
+	data map[string]interface{}
+}
+
+// transformUsers menjalankan fn ke setiap dokumen user, satu transaksi per
+// batch supaya DB tidak terkunci lama. fn mengembalikan true kalau dokumen
+// diubah; dokumen yang berubah disimpan dengan versi naik dan dicatat di
+// audit_log + user_snapshots (actor "migration:<name>"). fn harus idempotent
+// karena batch yang sudah commit akan diproses lagi kalau migration diulang.
+//
+// Cache user tidak ikut dihapus: entry lama kedaluwarsa sendiri, dan
+// penulisan di atas versi lama gagal dengan version conflict lalu diulang
+// dari database.
+func transformUsers(ctx context.Context, db *sql.DB, dialect repository.Dialect, name string, fn func(doc map[string]interface{}) bool) error {
+	cursor := ""
+	for {
+		count, last, err := transformBatch(ctx, db, dialect, name, cursor, fn)
+		if err != nil {
+			return err
+		}
+		if count < documentBatchSize {
+			return nil
+		}
+		cursor = last
+	}
+}
+
+func transformBatch(ctx context.Context, db *sql.DB, dialect repository.Dialect, name, cursor string, fn func(doc map[string]interface{}) bool) (int, string, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	query := dialect.Rebind("SELECT id, data FROM users WHERE id > ? ORDER BY id LIMIT ?")
+	if dialect == repository.Postgres {
+		query += " FOR UPDATE"
+	}
+	rows, err := tx.QueryContext(ctx, query, cursor, documentBatchSize)
+	if err != nil {
+		return 0, "", err
+	}
+	var batch []userRow
+	for rows.Next() {
+		var id, dataJSON string
+		if err := rows.Scan(&id, &dataJSON); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+		var data map[string]interface{}
+		json.Unmarshal([]byte(dataJSON), &data)
+		batch = append(batch, userRow{id: id, data: data})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+
+	now := time.Now().UnixMilli()
+	for _, row := range batch {
+		if row.data == nil {
+			continue // dokumen rusak dibiarkan
+		}
+		old := cloneDocument(row.data)
+		if !fn(row.data) {
+			continue
+		}
+		if err := writeDocument(ctx, tx, dialect, name, row.id, old, row.data, now); err != nil {
+			return 0, "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", err
+	}
+	if len(batch) == 0 {
+		return 0, cursor, nil
+	}
+	return len(batch), batch[len(batch)-1].id, nil
+}
+
+// writeDocument menyimpan dokumen hasil migration (kolom index ikut dihitung
+// ulang dari JSON) beserta audit dan snapshot-nya
+func writeDocument(ctx context.Context, tx *sql.Tx, dialect repository.Dialect, name, userID string, old, data map[string]interface{}, now int64) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// Di PostgreSQL kolom index adalah generated column
+	query := `
+	UPDATE users SET data = $1, version = version + 1
+	WHERE id = $2
+	RETURNING version`
+	args := []interface{}{string(dataBytes), userID}
+	if dialect == repository.SQLite {
+		user, err := entity.UserFromMap(data)
+		if user == nil {
+			return err
+		}
+		username, money, level := repository.IndexColumns(user)
+		query = `
+	UPDATE users SET data = ?, username = ?, money = ?, level = ?, version = version + 1
+	WHERE id = ?
+	RETURNING version`
+		args = []interface{}{string(dataBytes), username, money, level, userID}
+	}
+	var version int64
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&version); err != nil {
+		return err
+	}
+
+	changes, _ := json.Marshal(repository.DiffDocuments(old, data))
+	_, err = tx.ExecContext(ctx, dialect.Rebind(`
+	INSERT INTO audit_log (user_id, actor, reason, request_id, changes, created_at)
+	VALUES (?, ?, 'migration', '', ?, ?)`), userID, "migration:"+name, string(changes), now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, dialect.Rebind(`
+	INSERT INTO user_snapshots (user_id, version, data, created_at)
+	VALUES (?, ?, ?, ?)`), userID, version, string(dataBytes), now)
+	return err
+}
+
+func cloneDocument(doc map[string]interface{}) map[string]interface{} {
+	b, _ := json.Marshal(doc)
+	var clone map[string]interface{}
+	json.Unmarshal(b, &clone)
+	return clone
+}
@@ -0,0 +1,260 @@
+package migration
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration adalah satu langkah perubahan schema database. Migration SQL
+// berasal dari file sql/<dialect>/NNNN_nama.up.sql (dan .down.sql); migration
+// Go didaftarkan lewat register di file NNNN_nama.go dan berlaku untuk semua
+// dialect. Nomor versi yang sama berarti perubahan yang sama di tiap dialect.
+type Migration struct {
+	Version int
+	Name    string
+
+	// Migration SQL dijalankan dalam satu transaksi bersama pencatatannya
+	UpSQL   string
+	DownSQL string
+
+	// Migration Go mengatur transaksinya sendiri (misal per batch dokumen),
+	// jadi harus aman diulang kalau sempat gagal di tengah jalan
+	Up   func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error
+	Down func(ctx context.Context, db *sql.DB, dialect repository.Dialect) error
+}
+
+// Status adalah keadaan satu migration. AppliedAt 0 artinya belum dijalankan.
+type Status struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	AppliedAt int64  `json:"appliedAt"`
+}
+
+//go:embed sql/sqlite/*.sql sql/postgres/*.sql
+var sqlFiles embed.FS
+
+var goMigrations []Migration
+
+// register dipanggil dari init() file migration Go
+func register(m Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+const migrationsTableQuery = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at BIGINT NOT NULL
+);
+`
+
+type Migrator struct {
+	DB         *sql.DB
+	Dialect    repository.Dialect
+	Migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, dialect repository.Dialect) *Migrator {
+	if _, err := db.Exec(migrationsTableQuery); err != nil {
+		panic(err)
+	}
+
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		panic(err)
+	}
+	return &Migrator{DB: db, Dialect: dialect, Migrations: migrations}
+}
+
+// loadMigrations menggabungkan migration SQL (sesuai dialect) dan Go, urut
+// per versi
+func loadMigrations(dialect repository.Dialect) ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+
+	dir := "sql/" + string(dialect)
+	entries, err := sqlFiles.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		// 0001_create_users.up.sql -> versi 1, nama create_users, arah up
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		ext := path.Ext(base)
+		base = strings.TrimSuffix(base, ext)
+		rawVersion, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(rawVersion)
+		if !ok || err != nil || (ext != ".up" && ext != ".down") {
+			return nil, fmt.Errorf("nama file migration tidak valid: %s", entry.Name())
+		}
+
+		content, err := sqlFiles.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("versi migration %d dipakai dua kali (%s, %s)", version, m.Name, name)
+		}
+		if ext == ".up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	for _, gm := range goMigrations {
+		if existing, dup := byVersion[gm.Version]; dup {
+			return nil, fmt.Errorf("versi migration %d dipakai dua kali (%s, %s)", gm.Version, existing.Name, gm.Name)
+		}
+		m := gm
+		byVersion[gm.Version] = &m
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" && m.Up == nil {
+			return nil, fmt.Errorf("migration %04d_%s tidak punya langkah up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// applied mengembalikan waktu dijalankan per versi migration
+func (m *Migrator) applied(ctx context.Context) (map[int]int64, error) {
+	rows, err := m.DB.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int]int64)
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+// Status mengembalikan semua migration beserta kapan dijalankan
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(m.Migrations))
+	for _, mig := range m.Migrations {
+		result = append(result, Status{Version: mig.Version, Name: mig.Name, AppliedAt: applied[mig.Version]})
+	}
+	return result, nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan, urut dari versi
+// terkecil. Berhenti di migration pertama yang gagal.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.Migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, mig, true); err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down membatalkan steps migration terakhir yang sudah dijalankan
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.Migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := m.Migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.DownSQL == "" && mig.Down == nil {
+			return done, fmt.Errorf("migration %04d_%s tidak bisa di-rollback", mig.Version, mig.Name)
+		}
+		if err := m.run(ctx, mig, false); err != nil {
+			return done, fmt.Errorf("rollback %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+func (m *Migrator) run(ctx context.Context, mig Migration, up bool) error {
+	query, fn := mig.UpSQL, mig.Up
+	if !up {
+		query, fn = mig.DownSQL, mig.Down
+	}
+
+	if fn != nil {
+		if err := fn(ctx, m.DB, m.Dialect); err != nil {
+			return err
+		}
+		return record(ctx, m.DB, m.Dialect, mig, up)
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	if err := record(ctx, tx, m.Dialect, mig, up); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// record mencatat (up) atau menghapus (down) migration di schema_migrations
+func record(ctx context.Context, q dbtx, dialect repository.Dialect, mig Migration, up bool) error {
+	if !up {
+		_, err := q.ExecContext(ctx, dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+		return err
+	}
+	_, err := q.ExecContext(ctx,
+		dialect.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		mig.Version, mig.Name, time.Now().UnixMilli())
+	return err
+}
+
+// dbtx dipenuhi *sql.DB maupun *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+// openTestDB membuka SQLite baru (app.db di direktori sementara)
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	db, _, err := repository.OpenDB("sqlite", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db.Write
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := NewMigrator(db, repository.SQLite)
+
+	for i := 1; i < len(m.Migrations); i++ {
+		if m.Migrations[i].Version <= m.Migrations[i-1].Version {
+			t.Fatalf("versi migration tidak urut: %d setelah %d", m.Migrations[i].Version, m.Migrations[i-1].Version)
+		}
+	}
+
+	steps := []struct {
+		name    string
+		run     func() ([]Migration, error)
+		want    int
+		applied int
+	}{
+		{"up semua", func() ([]Migration, error) { return m.Up(ctx) }, len(m.Migrations), len(m.Migrations)},
+		{"up lagi tidak ada yang jalan", func() ([]Migration, error) { return m.Up(ctx) }, 0, len(m.Migrations)},
+		{"down 2", func() ([]Migration, error) { return m.Down(ctx, 2) }, 2, len(m.Migrations) - 2},
+		{"up sisanya", func() ([]Migration, error) { return m.Up(ctx) }, 2, len(m.Migrations)},
+		{"down semua", func() ([]Migration, error) { return m.Down(ctx, len(m.Migrations)) }, len(m.Migrations), 0},
+		{"up dari kosong", func() ([]Migration, error) { return m.Up(ctx) }, len(m.Migrations), len(m.Migrations)},
+	}
+	for _, step := range steps {
+		done, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if len(done) != step.want {
+			t.Errorf("%s: %d migration dijalankan, mau %d", step.name, len(done), step.want)
+		}
+		status, err := m.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		applied := 0
+		for _, s := range status {
+			if s.AppliedAt > 0 {
+				applied++
+			}
+		}
+		if applied != step.applied {
+			t.Errorf("%s: %d migration tercatat, mau %d", step.name, applied, step.applied)
+		}
+	}
+}
+
+func TestMergeHealth(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := NewMigrator(db, repository.SQLite)
+	all := m.Migrations
+	for i, mig := range all {
+		if mig.Version == 8 {
+			m.Migrations = all[:i]
+			break
+		}
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string]string{
+		"legacy":   `{"Health": 40, "rpg": {"health": 100, "level": 3.7}}`,
+		"keduanya": `{"Health": 40, "rpg": {"health": 70}}`,
+		"tanpaRpg": `{"Health": 55}`,
+		"baru":     `{"rpg": {"health": 80}}`,
+	}
+	for id, data := range docs {
+		if _, err := db.ExecContext(ctx, "INSERT INTO users (id, data) VALUES (?, ?)", id, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Migrations = all
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// kolom index dihitung sama seperti UserRepository.writeUser
+	var level interface{}
+	if err := db.QueryRowContext(ctx, "SELECT level FROM users WHERE id = 'legacy'").Scan(&level); err != nil {
+		t.Fatal(err)
+	}
+	if level != int64(3) {
+		t.Errorf("kolom level = %v (%T), mau 3", level, level)
+	}
+
+	tests := []struct {
+		id         string
+		wantHealth float64
+	}{
+		{"legacy", 40},
+		{"keduanya", 70},
+		{"tanpaRpg", 55},
+		{"baru", 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			var data string
+			if err := db.QueryRowContext(ctx, "SELECT data FROM users WHERE id = ?", tt.id).Scan(&data); err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal([]byte(data), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := doc["Health"]; ok {
+				t.Errorf("Health masih ada: %s", data)
+			}
+			if health := doc["rpg"].(map[string]interface{})["health"]; health != tt.wantHealth {
+				t.Errorf("rpg.health = %v, mau %v", health, tt.wantHealth)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+-- username, money, dan level dihitung otomatis dari dokumen (untuk index)
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	data JSONB NOT NULL,
+	username TEXT GENERATED ALWAYS AS (data->>'username') STORED,
+	money DOUBLE PRECISION GENERATED ALWAYS AS (COALESCE((data->>'money')::double precision, 0)) STORED,
+	level INTEGER GENERATED ALWAYS AS (COALESCE(floor((data->'rpg'->>'level')::double precision), 0)::integer) STORED
+);
+CREATE INDEX IF NOT EXISTS idx_money ON users(money);
+CREATE INDEX IF NOT EXISTS idx_level ON users(level);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS traffic_stats (
+	timestamp BIGINT PRIMARY KEY,
+	get_count BIGINT DEFAULT 0,
+	post_count BIGINT DEFAULT 0,
+	put_count BIGINT DEFAULT 0,
+	delete_count BIGINT DEFAULT 0
+);
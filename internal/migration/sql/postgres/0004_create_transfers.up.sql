@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS transfers (
+	id BIGSERIAL PRIMARY KEY,
+	from_id TEXT NOT NULL,
+	to_id TEXT NOT NULL,
+	field TEXT NOT NULL,
+	amount DOUBLE PRECISION NOT NULL,
+	note TEXT,
+	created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_transfers_from ON transfers(from_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transfers_to ON transfers(to_id, created_at);
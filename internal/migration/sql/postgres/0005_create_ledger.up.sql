@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS ledger (
+	id BIGSERIAL PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	field TEXT NOT NULL,
+	delta DOUBLE PRECISION NOT NULL,
+	balance_after DOUBLE PRECISION NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_user ON ledger(user_id, field, id);
+-- Trigger menolak UPDATE/DELETE supaya ledger benar-benar append-only
+CREATE OR REPLACE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS ledger_no_update ON ledger;
+CREATE TRIGGER ledger_no_update BEFORE UPDATE ON ledger
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+DROP TRIGGER IF EXISTS ledger_no_delete ON ledger;
+CREATE TRIGGER ledger_no_delete BEFORE DELETE ON ledger
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
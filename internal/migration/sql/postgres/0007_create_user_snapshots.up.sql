@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_snapshots (
+	id BIGSERIAL PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	version BIGINT NOT NULL,
+	data JSONB NOT NULL,
+	created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_snapshots_user ON user_snapshots(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_snapshots_created ON user_snapshots(created_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+	id BIGSERIAL PRIMARY KEY,
+	status TEXT NOT NULL,
+	cursor TEXT NOT NULL DEFAULT '',
+	total BIGINT NOT NULL DEFAULT 0,
+	processed BIGINT NOT NULL DEFAULT 0,
+	updated BIGINT NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	actor TEXT NOT NULL DEFAULT '',
+	started_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	finished_at BIGINT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,4 @@
+-- Kolom diamond dihitung dari dokumen untuk leaderboard wealth (money + diamond)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS diamond DOUBLE PRECISION
+	GENERATED ALWAYS AS (COALESCE((data->>'diamond')::double precision, 0)) STORED;
+CREATE INDEX IF NOT EXISTS idx_wealth ON users((money + diamond));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS season_results;
+DROP TABLE IF EXISTS season_baselines;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS seasons (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	types TEXT NOT NULL,
+	top_n INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	starts_at BIGINT NOT NULL,
+	ends_at BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	archived_at BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_seasons_status ON seasons(status, starts_at);
+
+-- Nilai setiap user saat season mulai, dasar peringkat "gained"
+CREATE TABLE IF NOT EXISTS season_baselines (
+	season_id BIGINT NOT NULL,
+	type TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	value DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (season_id, type, user_id)
+);
+
+-- Top-N setiap type saat season selesai (kind: total atau gained)
+CREATE TABLE IF NOT EXISTS season_results (
+	season_id BIGINT NOT NULL,
+	type TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	rank INTEGER NOT NULL,
+	user_id TEXT NOT NULL,
+	username TEXT,
+	value DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (season_id, type, kind, rank)
+);
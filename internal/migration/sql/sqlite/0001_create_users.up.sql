@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	username TEXT,
+	money REAL DEFAULT 0,
+	level INTEGER DEFAULT 0,
+	data TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_money ON users(money);
+CREATE INDEX IF NOT EXISTS idx_level ON users(level);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS traffic_stats (
+	timestamp INTEGER PRIMARY KEY,
+	get_count INTEGER DEFAULT 0,
+	post_count INTEGER DEFAULT 0,
+	put_count INTEGER DEFAULT 0,
+	delete_count INTEGER DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_ts ON traffic_stats(timestamp);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS transfers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_id TEXT NOT NULL,
+	to_id TEXT NOT NULL,
+	field TEXT NOT NULL,
+	amount REAL NOT NULL,
+	note TEXT,
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_transfers_from ON transfers(from_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transfers_to ON transfers(to_id, created_at);
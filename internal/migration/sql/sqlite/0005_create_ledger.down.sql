@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS ledger_no_update;
+DROP TRIGGER IF EXISTS ledger_no_delete;
+DROP TABLE IF EXISTS ledger;
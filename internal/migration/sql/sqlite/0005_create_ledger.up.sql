@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS ledger (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	field TEXT NOT NULL,
+	delta REAL NOT NULL,
+	balance_after REAL NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_user ON ledger(user_id, field, id);
+-- Trigger menolak UPDATE/DELETE supaya ledger benar-benar append-only
+CREATE TRIGGER IF NOT EXISTS ledger_no_update BEFORE UPDATE ON ledger
+BEGIN
+	SELECT RAISE(ABORT, 'ledger is append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS ledger_no_delete BEFORE DELETE ON ledger
+BEGIN
+	SELECT RAISE(ABORT, 'ledger is append-only');
+END;
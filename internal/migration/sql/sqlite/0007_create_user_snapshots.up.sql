@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	data TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_snapshots_user ON user_snapshots(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_snapshots_created ON user_snapshots(created_at);
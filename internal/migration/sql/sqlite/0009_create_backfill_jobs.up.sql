@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	status TEXT NOT NULL,
+	cursor TEXT NOT NULL DEFAULT '',
+	total INTEGER NOT NULL DEFAULT 0,
+	processed INTEGER NOT NULL DEFAULT 0,
+	updated INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	actor TEXT NOT NULL DEFAULT '',
+	started_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	finished_at INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_wealth;
+ALTER TABLE users DROP COLUMN diamond;
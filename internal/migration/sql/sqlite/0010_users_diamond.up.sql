@@ -0,0 +1,4 @@
+-- Kolom diamond dihitung dari dokumen (VIRTUAL, jadi tidak perlu diisi
+-- penulis) untuk leaderboard wealth (money + diamond)
+ALTER TABLE users ADD COLUMN diamond REAL GENERATED ALWAYS AS (IFNULL(json_extract(data, '$.diamond'), 0)) VIRTUAL;
+CREATE INDEX IF NOT EXISTS idx_wealth ON users(money + diamond);
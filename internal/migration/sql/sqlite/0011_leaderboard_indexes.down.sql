@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_money ON users(money);
+CREATE INDEX IF NOT EXISTS idx_level ON users(level);
+CREATE INDEX IF NOT EXISTS idx_wealth ON users(money + diamond);
+DROP INDEX IF EXISTS idx_money_id;
+DROP INDEX IF EXISTS idx_level_id;
+DROP INDEX IF EXISTS idx_wealth_id;
@@ -0,0 +1,8 @@
+-- Index leaderboard ditambah id supaya urutan nilai seri tetap dan
+-- pagination cursor (nilai, id) bisa langsung lewat index
+CREATE INDEX IF NOT EXISTS idx_money_id ON users(money, id);
+CREATE INDEX IF NOT EXISTS idx_level_id ON users(level, id);
+CREATE INDEX IF NOT EXISTS idx_wealth_id ON users(money + diamond, id);
+DROP INDEX IF EXISTS idx_money;
+DROP INDEX IF EXISTS idx_level;
+DROP INDEX IF EXISTS idx_wealth;
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FieldChange adalah perubahan satu field (dotted path, misal "rpg.exp")
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// AuditEntry adalah catatan satu kali penulisan dokumen user
+type AuditEntry struct {
+	ID        int64         `json:"id"`
+	UserID    string        `json:"userId"`
+	Actor     string        `json:"actor"`
+	Reason    string        `json:"reason"`
+	RequestID string        `json:"requestId"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt int64         `json:"createdAt"`
+}
+
+// DiffDocuments membandingkan dua dokumen sampai ke field nested. Field yang
+// hilang di salah satu sisi tercatat dengan nilai null. Hasil urut per path.
+func DiffDocuments(old, new map[string]interface{}) []FieldChange {
+	oldFlat := make(map[string]interface{})
+	newFlat := make(map[string]interface{})
+	flattenDocument("", old, oldFlat)
+	flattenDocument("", new, newFlat)
+
+	changes := []FieldChange{}
+	for path, oldVal := range oldFlat {
+		newVal, exists := newFlat[path]
+		if !exists {
+			changes = append(changes, FieldChange{Path: path, Old: oldVal, New: nil})
+		} else if !reflect.DeepEqual(oldVal, newVal) {
+			changes = append(changes, FieldChange{Path: path, Old: oldVal, New: newVal})
+		}
+	}
+	for path, newVal := range newFlat {
+		if _, exists := oldFlat[path]; !exists {
+			changes = append(changes, FieldChange{Path: path, Old: nil, New: newVal})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// flattenDocument mengubah object nested menjadi map dotted path -> nilai.
+// Object kosong dianggap nilai biasa supaya tetap kelihatan di diff.
+func flattenDocument(prefix string, doc map[string]interface{}, out map[string]interface{}) {
+	for k, v := range doc {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if obj, ok := v.(map[string]interface{}); ok && len(obj) > 0 {
+			flattenDocument(path, obj, out)
+			continue
+		}
+		out[path] = v
+	}
+}
+
+// recordAudit menyimpan diff penulisan (tidak ada baris kalau tidak ada yang berubah)
+func recordAudit(ctx context.Context, q dbtx, userID string, old, data map[string]interface{}) error {
+	changes := DiffDocuments(old, data)
+	if len(changes) == 0 {
+		return nil
+	}
+	changesJSON, _ := json.Marshal(changes)
+
+	query := `
+	INSERT INTO audit_log (user_id, actor, reason, request_id, changes, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := q.ExecContext(ctx, query, userID, ActorFrom(ctx), ReasonFrom(ctx), RequestIDFrom(ctx), string(changesJSON), time.Now().UnixMilli())
+	return err
+}
+
+// GetAuditLog mengambil catatan audit terbaru dulu. userID kosong berarti
+// semua user; since (ms) 0 berarti tanpa batas waktu.
+func (r *UserRepository) GetAuditLog(ctx context.Context, userID string, since int64, limit int) ([]AuditEntry, error) {
+	query := `
+	SELECT id, user_id, actor, reason, request_id, changes, created_at
+	FROM audit_log
+	WHERE (? = '' OR user_id = ?) AND created_at >= ?
+	ORDER BY id DESC
+	LIMIT ?`
+
+	rows, err := r.conn(r.ReadDB).QueryContext(ctx, query, userID, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var changesJSON string
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Actor, &e.Reason, &e.RequestID, &changesJSON, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(changesJSON), &e.Changes)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
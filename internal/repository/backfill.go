@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Status job backfill
+const (
+	BackfillRunning = "running"
+	BackfillFailed  = "failed"
+	BackfillDone    = "done"
+)
+
+// BackfillJob adalah progress pengisian field default ke semua user. Cursor
+// adalah id user terakhir yang sudah diproses, jadi job bisa dilanjutkan
+// setelah server mati di tengah jalan.
+type BackfillJob struct {
+	ID         int64  `json:"id"`
+	Status     string `json:"status"`
+	Cursor     string `json:"cursor"`
+	Total      int64  `json:"total"`
+	Processed  int64  `json:"processed"`
+	Updated    int64  `json:"updated"`
+	Error      string `json:"error,omitempty"`
+	Actor      string `json:"actor"`
+	StartedAt  int64  `json:"startedAt"`
+	UpdatedAt  int64  `json:"updatedAt"`
+	FinishedAt int64  `json:"finishedAt,omitempty"`
+}
+
+// CreateBackfillJob membuat job baru dengan total = jumlah user saat ini
+func (r *UserRepository) CreateBackfillJob(ctx context.Context, actor string) (*BackfillJob, error) {
+	now := time.Now().UnixMilli()
+	job := &BackfillJob{Status: BackfillRunning, Actor: actor, StartedAt: now, UpdatedAt: now}
+	if err := r.conn(r.ReadDB).QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&job.Total); err != nil {
+		return nil, err
+	}
+
+	query := `
+	INSERT INTO backfill_jobs (status, total, actor, started_at, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id`
+	err := r.conn(r.DB).QueryRowContext(ctx, query, job.Status, job.Total, actor, now, now).Scan(&job.ID)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetLatestBackfillJob mengambil job terakhir. nil kalau belum pernah ada.
+func (r *UserRepository) GetLatestBackfillJob(ctx context.Context) (*BackfillJob, error) {
+	query := `
+	SELECT id, status, cursor, total, processed, updated, error, actor, started_at, updated_at, finished_at
+	FROM backfill_jobs ORDER BY id DESC LIMIT 1`
+
+	var job BackfillJob
+	err := r.conn(r.ReadDB).QueryRowContext(ctx, query).Scan(&job.ID, &job.Status, &job.Cursor, &job.Total,
+		&job.Processed, &job.Updated, &job.Error, &job.Actor, &job.StartedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SaveBackfillJob menyimpan progress job
+func (r *UserRepository) SaveBackfillJob(ctx context.Context, job *BackfillJob) error {
+	job.UpdatedAt = time.Now().UnixMilli()
+	query := `
+	UPDATE backfill_jobs
+	SET status = ?, cursor = ?, total = ?, processed = ?, updated = ?, error = ?, updated_at = ?, finished_at = ?
+	WHERE id = ?`
+	_, err := r.conn(r.DB).ExecContext(ctx, query, job.Status, job.Cursor, job.Total, job.Processed,
+		job.Updated, job.Error, job.UpdatedAt, job.FinishedAt, job.ID)
+	return err
+}
+
+// GetUserIDsAfter mengambil id user setelah cursor (urut id), maksimal limit
+func (r *UserRepository) GetUserIDsAfter(ctx context.Context, cursor string, limit int) ([]string, error) {
+	rows, err := r.conn(r.ReadDB).QueryContext(ctx, "SELECT id FROM users WHERE id > ? ORDER BY id LIMIT ?", cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
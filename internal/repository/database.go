@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DSN default SQLite, file app.db di working directory. Koneksi baca
+// read-only dan tanpa shared cache supaya tidak ikut terkunci oleh penulis
+// (WAL mengizinkan baca bersamaan dengan satu penulis).
+const (
+	sqliteWriteDSN = "file:app.db?cache=shared&_journal_mode=WAL&_busy_timeout=5000"
+	sqliteReadDSN  = "file:app.db?mode=ro&_busy_timeout=5000"
+)
+
+// DB adalah handle database yang dipisah untuk tulis dan baca. Transaksi dan
+// penulisan lewat Write; query baca yang tidak perlu mengunci lewat Read.
+// Di PostgreSQL keduanya pool yang sama.
+type DB struct {
+	Write *sql.DB
+	Read  *sql.DB
+}
+
+func (db *DB) Close() error {
+	if db.Read == db.Write {
+		return db.Write.Close()
+	}
+	return errors.Join(db.Read.Close(), db.Write.Close())
+}
+
+// OpenDB membuka database sesuai DB_DRIVER ("sqlite" atau "postgres").
+// databaseURL hanya dipakai untuk postgres.
+func OpenDB(driver, databaseURL string) (*DB, Dialect, error) {
+	switch Dialect(driver) {
+	case "", SQLite:
+		writer, err := sql.Open("sqlite3", sqliteWriteDSN)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.SetMaxOpenConns(1) // Safe for SQLite Writer
+
+		// Koneksi baru dibuka saat query pertama, setelah migration membuat file DB
+		reader, err := sql.Open("sqlite3", sqliteReadDSN)
+		if err != nil {
+			writer.Close()
+			return nil, "", err
+		}
+		reader.SetMaxOpenConns(max(4, runtime.NumCPU()))
+		return &DB{Write: writer, Read: reader}, SQLite, nil
+	case Postgres:
+		if databaseURL == "" {
+			return nil, "", fmt.Errorf("DATABASE_URL wajib diisi untuk DB_DRIVER=postgres")
+		}
+		db, err := sql.Open("pgx", databaseURL)
+		if err != nil {
+			return nil, "", err
+		}
+		db.SetMaxOpenConns(20)
+		db.SetMaxIdleConns(5)
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return nil, "", err
+		}
+		return &DB{Write: db, Read: db}, Postgres, nil
+	default:
+		return nil, "", fmt.Errorf("DB_DRIVER tidak dikenal: %s", driver)
+	}
+}
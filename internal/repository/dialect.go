@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Dialect membedakan SQL untuk SQLite dan PostgreSQL. Query di package ini
+// ditulis dengan placeholder ? lalu diubah ke $1, $2, ... untuk PostgreSQL.
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+// Rebind mengubah placeholder ? menjadi bentuk yang dipakai dialect.
+// Tanda ? di dalam string literal ('...') tidak diubah.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			inString = !inString
+		case ch == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// rebindDB membungkus *sql.DB / *sql.Tx supaya query otomatis di-Rebind
+type rebindDB struct {
+	q       dbtx
+	dialect Dialect
+}
+
+func (r rebindDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.q.ExecContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+func (r rebindDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.q.QueryContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+func (r rebindDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.q.QueryRowContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+// wrap mengembalikan q yang query-nya di-Rebind sesuai dialect
+func (d Dialect) wrap(q dbtx) dbtx {
+	if d != Postgres {
+		return q
+	}
+	return rebindDB{q: q, dialect: d}
+}
+
+// dbtx dipenuhi *sql.DB maupun *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
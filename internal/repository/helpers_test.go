@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"Berpg/internal/migration"
+	"Berpg/internal/repository"
+	"context"
+	"os"
+	"testing"
+)
+
+// openTestDB membuka SQLite baru (app.db di direktori sementara) yang sudah
+// dimigrasi
+func openTestDB(t *testing.T) *repository.DB {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	db, dialect, err := repository.OpenDB("sqlite", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := migration.NewMigrator(db.Write, dialect).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// openPostgres membuka PostgreSQL dari TEST_DATABASE_URL (test dilewati kalau
+// kosong) dan menjalankan migration. Data lama di DB tersebut tidak dihapus.
+func openPostgres(t *testing.T) *repository.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL kosong")
+	}
+	db, dialect, err := repository.OpenDB("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := migration.NewMigrator(db.Write, dialect).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
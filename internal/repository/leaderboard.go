@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// leaderboardColumns: tipe leaderboard yang punya kolom sendiri. money,
+// level, dan wealth punya index (nilai, id) sehingga halaman mana pun
+// dibaca langsung dari index; tipe lain dihitung dari JSON dokumen setiap
+// request (scan seluruh tabel, cukup untuk puluhan ribu user).
+var leaderboardColumns = map[string]string{
+	"money":     "money",
+	"level":     "level",
+	"rpg.level": "level",
+	"diamond":   "diamond",
+	"wealth":    "money + diamond",
+}
+
+// IsLeaderboardType melaporkan apakah lbType bisa dipakai untuk leaderboard:
+// "wealth", "level", atau path field angka di schema user (misal "money",
+// "rpg.exp", "subscribers")
+func IsLeaderboardType(lbType string) bool {
+	_, ok := leaderboardColumns[lbType]
+	return ok || entity.IsNumericPath(lbType)
+}
+
+// LeaderboardQuery memilih satu halaman leaderboard. Halaman berikutnya
+// diambil dengan Offset atau, lebih murah untuk halaman jauh, dengan After
+// berisi entry terakhir halaman sebelumnya.
+type LeaderboardQuery struct {
+	Type   string
+	Asc    bool // true = nilai terkecil dulu
+	Limit  int
+	Offset int
+	After  *LeaderboardCursor
+}
+
+// LeaderboardCursor adalah posisi satu entry leaderboard. Nilai seri
+// diurutkan dengan userId searah urutan nilai.
+type LeaderboardCursor struct {
+	Value  float64
+	UserID string
+}
+
+// LeaderboardEntry adalah satu baris leaderboard beserta nilai yang diurutkan
+type LeaderboardEntry struct {
+	User  *entity.User
+	Value float64
+}
+
+// leaderboardExpr mengembalikan ekspresi SQL nilai leaderboard dari kolom
+// tabel users. Path JSON boleh ditulis langsung di query karena sudah dicek
+// ke schema user (hanya huruf, angka, dan titik).
+func leaderboardExpr(dialect Dialect, lbType string) (string, error) {
+	if column, ok := leaderboardColumns[lbType]; ok {
+		return column, nil
+	}
+	if !entity.IsNumericPath(lbType) {
+		return "", fmt.Errorf("tipe leaderboard tidak dikenal: %s", lbType)
+	}
+	if dialect == Postgres {
+		path := "{" + strings.ReplaceAll(lbType, ".", ",") + "}"
+		return fmt.Sprintf("COALESCE((data #>> '%s')::double precision, 0)", path), nil
+	}
+	return fmt.Sprintf("IFNULL(json_extract(data, '$.%s'), 0)", lbType), nil
+}
+
+// GetLeaderboard mengambil satu halaman leaderboard urut nilai lalu userId
+func (r *UserRepository) GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardEntry, error) {
+	expr, err := leaderboardExpr(r.Dialect, q.Type)
+	if err != nil {
+		return nil, err
+	}
+	dir, cmp := "DESC", "<"
+	if q.Asc {
+		dir, cmp = "ASC", ">"
+	}
+
+	// Urutkan id + nilai dulu, dokumen baru dibaca untuk baris halaman ini
+	// saja (baris yang dilewati offset tidak ikut dibaca dari tabel)
+	inner := "SELECT id, " + expr + " AS value FROM users"
+	var args []interface{}
+	if q.After != nil {
+		inner += " WHERE (" + expr + ", id) " + cmp + " (?, ?)"
+		args = append(args, q.After.Value, q.After.UserID)
+	}
+	inner += " ORDER BY " + expr + " " + dir + ", id " + dir + " LIMIT ? OFFSET ?"
+	args = append(args, q.Limit, q.Offset)
+	return queryLeaderboard(ctx, r.conn(r.ReadDB), inner, dir, args...)
+}
+
+// queryLeaderboard membaca dokumen user untuk hasil query inner (kolom id
+// dan value), urut value lalu id searah dir
+func queryLeaderboard(ctx context.Context, q dbtx, inner, dir string, args ...interface{}) ([]LeaderboardEntry, error) {
+	query := "SELECT u.id, u.data, lb.value FROM (" + inner + ") lb JOIN users u ON u.id = lb.id" +
+		" ORDER BY lb.value " + dir + ", lb.id " + dir
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var id, dataJSON string
+		var value float64
+		if err := rows.Scan(&id, &dataJSON, &value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, LeaderboardEntry{User: decodeUserDoc(id, dataJSON), Value: value})
+	}
+	return entries, rows.Err()
+}
+
+// GetLeaderboardPosition mengembalikan posisi userID di leaderboard
+// (nilai + userId, bisa dipakai sebagai LeaderboardQuery.After) dan jumlah
+// user di depannya. ErrUserNotFound kalau user belum tersimpan.
+func (r *UserRepository) GetLeaderboardPosition(ctx context.Context, lbType string, asc bool, userID string) (*LeaderboardCursor, int, error) {
+	expr, err := leaderboardExpr(r.Dialect, lbType)
+	if err != nil {
+		return nil, 0, err
+	}
+	cmp := ">"
+	if asc {
+		cmp = "<"
+	}
+
+	// Satu statement supaya nilai dan hitungan dari snapshot yang sama
+	query := "SELECT me.value, (SELECT COUNT(*) FROM users WHERE (" + expr + ", id) " + cmp + " (me.value, me.id))" +
+		" FROM (SELECT id, " + expr + " AS value FROM users WHERE id = ?) me"
+	var value float64
+	var ahead int
+	err = r.conn(r.ReadDB).QueryRowContext(ctx, query, userID).Scan(&value, &ahead)
+	if err == sql.ErrNoRows {
+		return nil, 0, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return &LeaderboardCursor{Value: value, UserID: userID}, ahead, nil
+}
+
+// leaderboardValue menghitung nilai leaderboard dari JSON dokumen, sama
+// seperti ekspresi leaderboardExpr
+func leaderboardValue(dataJSON, lbType string) float64 {
+	var doc map[string]interface{}
+	json.Unmarshal([]byte(dataJSON), &doc)
+	number := func(path string) float64 {
+		var val interface{} = doc
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := val.(map[string]interface{})
+			if !ok {
+				return 0
+			}
+			val = obj[key]
+		}
+		n, _ := val.(float64)
+		return n
+	}
+
+	switch lbType {
+	case "level", "rpg.level":
+		return math.Floor(number("rpg.level"))
+	case "wealth":
+		return number("money") + number("diamond")
+	}
+	return number(lbType)
+}
+
+// CountUsers menghitung jumlah user tersimpan
+func (r *UserRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := r.conn(r.ReadDB).QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
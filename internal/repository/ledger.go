@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// LedgerFields adalah field mata uang yang setiap perubahannya dicatat
+var LedgerFields = []string{
+	"money", "bank", "diamond", "balance", "saldo", "atm", "coin", "chip",
+	"gems", "emerald", "litecoin", "tiketcoin", "poin", "cupon",
+	"ovo", "dana", "gopay",
+}
+
+// LedgerEntry adalah satu baris ledger (append-only)
+type LedgerEntry struct {
+	ID           int64   `json:"id"`
+	UserID       string  `json:"userId"`
+	Field        string  `json:"field"`
+	Delta        float64 `json:"delta"`
+	BalanceAfter float64 `json:"balanceAfter"`
+	Reason       string  `json:"reason"`
+	RequestID    string  `json:"requestId"`
+	CreatedAt    int64   `json:"createdAt"`
+}
+
+// ledgerEntries menghitung entry ledger (selisih setiap field mata uang)
+// antara old dan data. Keterangan penulisan diambil dari ctx.
+func ledgerEntries(ctx context.Context, userID string, old, data map[string]interface{}) []LedgerEntry {
+	var entries []LedgerEntry
+	now := time.Now().UnixMilli()
+	for _, field := range LedgerFields {
+		before, _ := old[field].(float64)
+		after, _ := data[field].(float64)
+		if before == after {
+			continue
+		}
+		entries = append(entries, LedgerEntry{
+			UserID:       userID,
+			Field:        field,
+			Delta:        after - before,
+			BalanceAfter: after,
+			Reason:       ReasonFrom(ctx),
+			RequestID:    RequestIDFrom(ctx),
+			CreatedAt:    now,
+		})
+	}
+	return entries
+}
+
+// recordLedger mencatat selisih setiap field mata uang antara old dan data
+func recordLedger(ctx context.Context, q dbtx, userID string, old, data map[string]interface{}) error {
+	for _, e := range ledgerEntries(ctx, userID, old, data) {
+		query := `
+		INSERT INTO ledger (user_id, field, delta, balance_after, reason, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+		if _, err := q.ExecContext(ctx, query, e.UserID, e.Field, e.Delta, e.BalanceAfter, e.Reason, e.RequestID, e.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistory mengambil riwayat ledger user, terbaru dulu. field kosong berarti
+// semua field. cursor adalah id entry terakhir dari halaman sebelumnya (0
+// untuk halaman pertama); nextCursor 0 artinya tidak ada halaman lagi.
+func (r *UserRepository) GetHistory(ctx context.Context, userID, field string, limit int, cursor int64) ([]LedgerEntry, int64, error) {
+	query := `
+	SELECT id, user_id, field, delta, balance_after, reason, request_id, created_at
+	FROM ledger
+	WHERE user_id = ? AND (? = '' OR field = ?) AND (? = 0 OR id < ?)
+	ORDER BY id DESC
+	LIMIT ?`
+
+	// Ambil satu lebih untuk tahu apakah masih ada halaman berikutnya
+	rows, err := r.conn(r.ReadDB).QueryContext(ctx, query, userID, field, field, cursor, cursor, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []LedgerEntry{}
+	for rows.Next() {
+		var e LedgerEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Field, &e.Delta, &e.BalanceAfter, &e.Reason, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor int64
+	if len(entries) > limit {
+		entries = entries[:limit]
+		nextCursor = entries[len(entries)-1].ID
+	}
+	return entries, nextCursor, nil
+}
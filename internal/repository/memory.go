@@ -0,0 +1,620 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository adalah UserStore yang seluruh datanya ada di memori
+// proses (hilang saat restart). Dipakai untuk development dan test; aturan
+// versi, ledger, audit, dan snapshot sama dengan UserRepository.
+type MemoryUserRepository struct {
+	mu        sync.Mutex
+	users     map[string]memoryUser
+	ledger    []LedgerEntry
+	audit     []AuditEntry
+	snapshots []memorySnapshot
+	transfers []Transfer
+	jobs      []BackfillJob
+}
+
+// dokumen disimpan sebagai JSON supaya pemanggil tidak berbagi pointer
+type memoryUser struct {
+	data    string
+	version int64
+}
+
+type memorySnapshot struct {
+	id        int64
+	userID    string
+	version   int64
+	data      string
+	createdAt int64
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[string]memoryUser)}
+}
+
+func (r *MemoryUserRepository) GetUser(ctx context.Context, userID string) (*entity.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return nil, 0, nil
+	}
+	return decodeUserDoc(userID, stored.data), stored.version, nil
+}
+
+func (r *MemoryUserRepository) SaveUser(ctx context.Context, userID string, user *entity.User, expectedVersion int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, version, err := r.write(ctx, userID, user, expectedVersion)
+	return version, err
+}
+
+// MutateUser: fn tidak boleh memanggil method repository lain (mutex ditahan)
+func (r *MemoryUserRepository) MutateUser(ctx context.Context, userID string, fn func(user *entity.User) error) (*entity.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return nil, 0, ErrUserNotFound
+	}
+	if err := checkExpectedVersion(ctx, stored.version); err != nil {
+		return nil, 0, err
+	}
+	user := decodeUserDoc(userID, stored.data)
+	if err := fn(user); err != nil {
+		return nil, 0, err
+	}
+
+	_, version, err := r.write(ctx, userID, user, stored.version)
+	if err != nil {
+		return nil, 0, err
+	}
+	return user, version, nil
+}
+
+func (r *MemoryUserRepository) MutateUsers(ctx context.Context, userIDs []string, fn func(users map[string]*entity.User) error, transfers []Transfer) (map[string]*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make(map[string]*entity.User, len(userIDs))
+	for _, userID := range userIDs {
+		stored, ok := r.users[userID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+		}
+		users[userID] = decodeUserDoc(userID, stored.data)
+	}
+	if err := fn(users); err != nil {
+		return nil, err
+	}
+
+	// Semua dokumen di-encode dulu supaya tidak ada yang tertulis setengah jalan
+	encoded := make(map[string]string, len(users))
+	for userID, user := range users {
+		dataBytes, err := json.Marshal(user)
+		if err != nil {
+			return nil, err
+		}
+		encoded[userID] = string(dataBytes)
+	}
+	for userID, dataStr := range encoded {
+		r.store(ctx, userID, dataStr, r.users[userID].version+1)
+	}
+	r.transfers = append(r.transfers, transfers...)
+	return users, nil
+}
+
+// write menerapkan aturan expectedVersion yang sama dengan writeUser
+func (r *MemoryUserRepository) write(ctx context.Context, userID string, user *entity.User, expectedVersion int64) (string, int64, error) {
+	dataBytes, err := json.Marshal(user)
+	if err != nil {
+		return "", 0, err
+	}
+
+	stored, exists := r.users[userID]
+	switch {
+	case expectedVersion > 0 && (!exists || stored.version != expectedVersion):
+		return "", 0, ErrVersionConflict
+	case expectedVersion == 0 && exists:
+		return "", 0, ErrVersionConflict
+	}
+
+	newVersion := stored.version + 1
+	r.store(ctx, userID, string(dataBytes), newVersion)
+	return string(dataBytes), newVersion, nil
+}
+
+// store menyimpan dokumen beserta ledger, audit, dan snapshot-nya
+func (r *MemoryUserRepository) store(ctx context.Context, userID, dataStr string, version int64) {
+	var old map[string]interface{}
+	if stored, exists := r.users[userID]; exists {
+		old = decodeUser(stored.data)
+	}
+	r.users[userID] = memoryUser{data: dataStr, version: version}
+
+	now := time.Now().UnixMilli()
+	data := decodeUser(dataStr)
+	for _, e := range ledgerEntries(ctx, userID, old, data) {
+		e.ID = int64(len(r.ledger) + 1)
+		r.ledger = append(r.ledger, e)
+	}
+	if changes := DiffDocuments(old, data); len(changes) > 0 {
+		r.audit = append(r.audit, AuditEntry{
+			ID:        int64(len(r.audit) + 1),
+			UserID:    userID,
+			Actor:     ActorFrom(ctx),
+			Reason:    ReasonFrom(ctx),
+			RequestID: RequestIDFrom(ctx),
+			Changes:   changes,
+			CreatedAt: now,
+		})
+	}
+
+	var lastID int64
+	if len(r.snapshots) > 0 {
+		lastID = r.snapshots[len(r.snapshots)-1].id
+	}
+	r.snapshots = append(r.snapshots, memorySnapshot{
+		id: lastID + 1, userID: userID, version: version, data: dataStr, createdAt: now,
+	})
+}
+
+func (r *MemoryUserRepository) GetAFKUsers(ctx context.Context) (map[string]*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]*entity.User)
+	for id, stored := range r.users {
+		user := decodeUserDoc(id, stored.data)
+		if user.Afk > 0 {
+			user.ID = id
+			result[id] = user
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryUserRepository) GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardEntry, error) {
+	if !IsLeaderboardType(q.Type) {
+		return nil, fmt.Errorf("tipe leaderboard tidak dikenal: %s", q.Type)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]LeaderboardEntry, 0, len(r.users))
+	for id, stored := range r.users {
+		entries = append(entries, LeaderboardEntry{
+			User:  decodeUserDoc(id, stored.data),
+			Value: leaderboardValue(stored.data, q.Type),
+		})
+	}
+	// less: urutan naik (nilai lalu id); urutan turun tinggal dibalik
+	less := func(a, b LeaderboardCursor) bool {
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.UserID < b.UserID
+	}
+	before := func(a, b LeaderboardCursor) bool {
+		if q.Asc {
+			return less(a, b)
+		}
+		return less(b, a)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return before(
+			LeaderboardCursor{entries[i].Value, entries[i].User.ID},
+			LeaderboardCursor{entries[j].Value, entries[j].User.ID})
+	})
+
+	if q.After != nil {
+		start := sort.Search(len(entries), func(i int) bool {
+			return before(*q.After, LeaderboardCursor{entries[i].Value, entries[i].User.ID})
+		})
+		entries = entries[start:]
+	}
+	if q.Offset >= len(entries) {
+		return nil, nil
+	}
+	entries = entries[q.Offset:]
+	if q.Limit < len(entries) {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+func (r *MemoryUserRepository) GetLeaderboardPosition(ctx context.Context, lbType string, asc bool, userID string) (*LeaderboardCursor, int, error) {
+	if !IsLeaderboardType(lbType) {
+		return nil, 0, fmt.Errorf("tipe leaderboard tidak dikenal: %s", lbType)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	me, ok := r.users[userID]
+	if !ok {
+		return nil, 0, ErrUserNotFound
+	}
+	value := leaderboardValue(me.data, lbType)
+	ahead := 0
+	for id, stored := range r.users {
+		v := leaderboardValue(stored.data, lbType)
+		if v == value {
+			if (id > userID) != asc && id != userID {
+				ahead++
+			}
+		} else if (v > value) != asc {
+			ahead++
+		}
+	}
+	return &LeaderboardCursor{Value: value, UserID: userID}, ahead, nil
+}
+
+func (r *MemoryUserRepository) CountUsers(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.users), nil
+}
+
+func (r *MemoryUserRepository) GetHistory(ctx context.Context, userID, field string, limit int, cursor int64) ([]LedgerEntry, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := []LedgerEntry{}
+	for i := len(r.ledger) - 1; i >= 0 && len(entries) <= limit; i-- {
+		e := r.ledger[i]
+		if e.UserID != userID || (field != "" && e.Field != field) || (cursor != 0 && e.ID >= cursor) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	var nextCursor int64
+	if len(entries) > limit {
+		entries = entries[:limit]
+		nextCursor = entries[len(entries)-1].ID
+	}
+	return entries, nextCursor, nil
+}
+
+func (r *MemoryUserRepository) GetAuditLog(ctx context.Context, userID string, since int64, limit int) ([]AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := []AuditEntry{}
+	for i := len(r.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		e := r.audit[i]
+		if (userID != "" && e.UserID != userID) || e.CreatedAt < since {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (r *MemoryUserRepository) GetSnapshotAt(ctx context.Context, userID string, at int64) (*UserSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Snapshot tersimpan urut waktu, jadi yang terakhir cocok adalah yang terbaru
+	for i := len(r.snapshots) - 1; i >= 0; i-- {
+		s := r.snapshots[i]
+		if s.userID == userID && s.createdAt <= at {
+			return &UserSnapshot{
+				UserID:    userID,
+				Version:   s.version,
+				User:      decodeUserDoc(userID, s.data),
+				CreatedAt: s.createdAt,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryUserRepository) PruneSnapshots(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := make(map[string]int64)
+	for _, s := range r.snapshots {
+		latest[s.userID] = s.id
+	}
+
+	var pruned int64
+	kept := r.snapshots[:0]
+	for _, s := range r.snapshots {
+		if s.createdAt < before.UnixMilli() && latest[s.userID] != s.id {
+			pruned++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	r.snapshots = kept
+	return pruned, nil
+}
+
+func (r *MemoryUserRepository) CreateBackfillJob(ctx context.Context, actor string) (*BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	job := BackfillJob{
+		ID:        int64(len(r.jobs) + 1),
+		Status:    BackfillRunning,
+		Total:     int64(len(r.users)),
+		Actor:     actor,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	r.jobs = append(r.jobs, job)
+	return &job, nil
+}
+
+func (r *MemoryUserRepository) GetLatestBackfillJob(ctx context.Context) (*BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.jobs) == 0 {
+		return nil, nil
+	}
+	job := r.jobs[len(r.jobs)-1]
+	return &job, nil
+}
+
+func (r *MemoryUserRepository) SaveBackfillJob(ctx context.Context, job *BackfillJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.UpdatedAt = time.Now().UnixMilli()
+	for i := range r.jobs {
+		if r.jobs[i].ID == job.ID {
+			r.jobs[i] = *job
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) GetUserIDsAfter(ctx context.Context, cursor string, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for id := range r.users {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// MemoryStatsRepository adalah StatsStore di memori proses
+type MemoryStatsRepository struct {
+	mu     sync.Mutex
+	counts map[int64]map[string]int64
+}
+
+func NewMemoryStatsRepository() *MemoryStatsRepository {
+	return &MemoryStatsRepository{counts: make(map[int64]map[string]int64)}
+}
+
+func (r *MemoryStatsRepository) LogTraffic(method string) {
+	switch method {
+	case "GET", "POST", "PUT", "DELETE":
+	default:
+		return
+	}
+
+	ts := time.Now().Truncate(time.Minute).UnixMilli()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts[ts] == nil {
+		r.counts[ts] = make(map[string]int64)
+	}
+	r.counts[ts][method]++
+}
+
+func (r *MemoryStatsRepository) GetHourlyStats(ctx context.Context) ([]map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endTs := time.Now().Truncate(time.Minute).UnixMilli()
+	var result []map[string]interface{}
+	for ts := endTs - 60*60000; ts <= endTs; ts += 60000 {
+		counts := r.counts[ts]
+		result = append(result, map[string]interface{}{
+			"timestamp": ts,
+			"GET":       counts["GET"],
+			"POST":      counts["POST"],
+			"PUT":       counts["PUT"],
+			"DELETE":    counts["DELETE"],
+		})
+	}
+	return result, nil
+}
+
+func (r *MemoryStatsRepository) ResetStats() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts = make(map[int64]map[string]int64)
+	return nil
+}
+
+// MemorySeasonRepository adalah SeasonStore di memori proses, membaca user
+// dari MemoryUserRepository
+type MemorySeasonRepository struct {
+	Users *MemoryUserRepository
+
+	mu        sync.Mutex
+	seasons   []Season
+	baselines map[int64]map[string]map[string]float64 // season -> type -> user
+	results   map[int64]map[string][]SeasonResult     // season -> type/kind
+}
+
+func NewMemorySeasonRepository(users *MemoryUserRepository) *MemorySeasonRepository {
+	return &MemorySeasonRepository{
+		Users:     users,
+		baselines: make(map[int64]map[string]map[string]float64),
+		results:   make(map[int64]map[string][]SeasonResult),
+	}
+}
+
+func (r *MemorySeasonRepository) CreateSeason(ctx context.Context, s *Season) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.ID = int64(len(r.seasons) + 1)
+	s.Status = SeasonScheduled
+	s.CreatedAt = time.Now().UnixMilli()
+	r.seasons = append(r.seasons, *s)
+	return nil
+}
+
+func (r *MemorySeasonRepository) GetSeason(ctx context.Context, id int64) (*Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id < 1 || id > int64(len(r.seasons)) {
+		return nil, nil
+	}
+	s := r.seasons[id-1]
+	return &s, nil
+}
+
+func (r *MemorySeasonRepository) ListSeasons(ctx context.Context) ([]Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seasons := append([]Season{}, r.seasons...)
+	sort.Slice(seasons, func(i, j int) bool {
+		if seasons[i].StartsAt != seasons[j].StartsAt {
+			return seasons[i].StartsAt > seasons[j].StartsAt
+		}
+		return seasons[i].ID > seasons[j].ID
+	})
+	return seasons, nil
+}
+
+func (r *MemorySeasonRepository) StartSeason(ctx context.Context, s *Season) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	baselines := make(map[string]map[string]float64)
+	r.Users.mu.Lock()
+	for _, lbType := range s.Types {
+		values := make(map[string]float64, len(r.Users.users))
+		for id, stored := range r.Users.users {
+			values[id] = leaderboardValue(stored.data, lbType)
+		}
+		baselines[lbType] = values
+	}
+	r.Users.mu.Unlock()
+
+	r.baselines[s.ID] = baselines
+	s.Status = SeasonActive
+	r.seasons[s.ID-1].Status = SeasonActive
+	return nil
+}
+
+func (r *MemorySeasonRepository) ArchiveSeason(ctx context.Context, s *Season) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make(map[string][]SeasonResult)
+	for _, lbType := range s.Types {
+		total, err := r.Users.GetLeaderboard(ctx, LeaderboardQuery{Type: lbType, Limit: s.TopN})
+		if err != nil {
+			return err
+		}
+		gained := r.gained(s.ID, lbType)
+		if s.TopN < len(gained) {
+			gained = gained[:s.TopN]
+		}
+		for kind, entries := range map[string][]LeaderboardEntry{SeasonTotal: total, SeasonGained: gained} {
+			for i, e := range entries {
+				results[lbType+"/"+kind] = append(results[lbType+"/"+kind], SeasonResult{
+					Rank: i + 1, UserID: e.User.ID, Username: e.User.Username, Value: e.Value,
+				})
+			}
+		}
+	}
+
+	r.results[s.ID] = results
+	delete(r.baselines, s.ID)
+	s.Status = SeasonEnded
+	s.ArchivedAt = time.Now().UnixMilli()
+	r.seasons[s.ID-1] = *s
+	return nil
+}
+
+func (r *MemorySeasonRepository) GetSeasonGained(ctx context.Context, seasonID int64, lbType string, limit, offset int) ([]LeaderboardEntry, error) {
+	if !IsLeaderboardType(lbType) {
+		return nil, fmt.Errorf("tipe leaderboard tidak dikenal: %s", lbType)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.gained(seasonID, lbType)
+	if offset >= len(entries) {
+		return nil, nil
+	}
+	entries = entries[offset:]
+	if limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// gained menghitung kenaikan semua user, urut turun (nilai lalu id)
+func (r *MemorySeasonRepository) gained(seasonID int64, lbType string) []LeaderboardEntry {
+	baseline := r.baselines[seasonID][lbType]
+	fallback := seasonBaselineDefault(lbType)
+
+	r.Users.mu.Lock()
+	entries := make([]LeaderboardEntry, 0, len(r.Users.users))
+	for id, stored := range r.Users.users {
+		base, ok := baseline[id]
+		if !ok {
+			base = fallback
+		}
+		entries = append(entries, LeaderboardEntry{
+			User:  decodeUserDoc(id, stored.data),
+			Value: leaderboardValue(stored.data, lbType) - base,
+		})
+	}
+	r.Users.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].User.ID > entries[j].User.ID
+	})
+	return entries
+}
+
+func (r *MemorySeasonRepository) GetSeasonResults(ctx context.Context, seasonID int64, lbType, kind string, limit, offset int) ([]SeasonResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := r.results[seasonID][lbType+"/"+kind]
+	if offset >= len(results) {
+		return []SeasonResult{}, nil
+	}
+	results = results[offset:]
+	if limit < len(results) {
+		results = results[:limit]
+	}
+	return append([]SeasonResult{}, results...), nil
+}
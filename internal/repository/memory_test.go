@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"errors"
+	"testing"
+)
+
+// newMemoryRepo: money a=300, b=200, c=200, d=100
+func newMemoryRepo(t *testing.T) *MemoryUserRepository {
+	t.Helper()
+	r := NewMemoryUserRepository()
+	for id, money := range map[string]float64{"a": 300, "b": 200, "c": 200, "d": 100} {
+		user := entity.NewUser()
+		user.ID, user.Money = id, money
+		if _, err := r.SaveUser(context.Background(), id, user, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestMemoryLeaderboard(t *testing.T) {
+	r := newMemoryRepo(t)
+	tests := []struct {
+		name string
+		q    LeaderboardQuery
+		want []string
+	}{
+		{"desc", LeaderboardQuery{Type: "money", Limit: 10}, []string{"a", "c", "b", "d"}},
+		{"asc", LeaderboardQuery{Type: "money", Asc: true, Limit: 10}, []string{"d", "b", "c", "a"}},
+		{"limit dan offset", LeaderboardQuery{Type: "money", Limit: 2, Offset: 1}, []string{"c", "b"}},
+		{"setelah cursor seri", LeaderboardQuery{Type: "money", Limit: 10, After: &LeaderboardCursor{200, "c"}}, []string{"b", "d"}},
+		{"setelah cursor user yang sudah hilang", LeaderboardQuery{Type: "money", Limit: 10, After: &LeaderboardCursor{250, "x"}}, []string{"c", "b", "d"}},
+		{"offset lewat akhir", LeaderboardQuery{Type: "money", Limit: 10, Offset: 9}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := r.GetLeaderboard(context.Background(), tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.User.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ids = %v, mau %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ids = %v, mau %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryLeaderboardPosition(t *testing.T) {
+	r := newMemoryRepo(t)
+	tests := []struct {
+		userID string
+		asc    bool
+		ahead  int
+	}{
+		{"a", false, 0},
+		{"c", false, 1},
+		{"b", false, 2},
+		{"d", false, 3},
+		{"b", true, 1},
+		{"c", true, 2},
+	}
+	for _, tt := range tests {
+		pos, ahead, err := r.GetLeaderboardPosition(context.Background(), "money", tt.asc, tt.userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ahead != tt.ahead || pos.UserID != tt.userID {
+			t.Errorf("posisi %s asc=%v = %d, mau %d", tt.userID, tt.asc, ahead, tt.ahead)
+		}
+	}
+	if _, _, err := r.GetLeaderboardPosition(context.Background(), "money", false, "x"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user tidak ada = %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Status season
+const (
+	SeasonScheduled = "scheduled"
+	SeasonActive    = "active"
+	SeasonEnded     = "ended"
+)
+
+// Jenis peringkat season: total = nilai saat season selesai, gained =
+// kenaikan sejak season mulai
+const (
+	SeasonTotal  = "total"
+	SeasonGained = "gained"
+)
+
+// Season adalah satu periode kompetisi. Saat mulai, nilai setiap user untuk
+// setiap Types dicatat sebagai baseline; saat selesai, TopN peringkat total
+// dan gained disimpan di season_results lalu baseline dihapus.
+type Season struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Types      []string `json:"types"`
+	TopN       int      `json:"topN"`
+	Status     string   `json:"status"`
+	StartsAt   int64    `json:"startsAt"`
+	EndsAt     int64    `json:"endsAt"`
+	CreatedAt  int64    `json:"createdAt"`
+	ArchivedAt int64    `json:"archivedAt,omitempty"`
+}
+
+// SeasonResult adalah satu baris hasil akhir season
+type SeasonResult struct {
+	Rank     int     `json:"rank"`
+	UserID   string  `json:"userId"`
+	Username string  `json:"username"`
+	Value    float64 `json:"value"`
+}
+
+// SeasonRepository adalah SeasonStore di atas database SQL yang sama dengan
+// UserRepository (membaca tabel users langsung)
+type SeasonRepository struct {
+	DB      *sql.DB // penulis
+	ReadDB  *sql.DB
+	Dialect Dialect
+
+	// Flush dipanggil sebelum mengambil atau mengarsip baseline supaya
+	// penulisan yang masih antri ikut terhitung (WriteBehindRepository.Flush).
+	// Peringkat season berjalan tidak flush, jadi bisa tertinggal paling lama
+	// sekitar interval write-behind. nil = tidak ada.
+	Flush func(ctx context.Context) error
+}
+
+// NewSeasonRepository butuh tabel seasons dari internal/migration
+func NewSeasonRepository(db *DB, dialect Dialect) *SeasonRepository {
+	return &SeasonRepository{DB: db.Write, ReadDB: db.Read, Dialect: dialect}
+}
+
+func (r *SeasonRepository) flush(ctx context.Context) error {
+	if r.Flush == nil {
+		return nil
+	}
+	return r.Flush(ctx)
+}
+
+// CreateSeason menyimpan season baru (status scheduled) dan mengisi ID-nya
+func (r *SeasonRepository) CreateSeason(ctx context.Context, s *Season) error {
+	types, _ := json.Marshal(s.Types)
+	s.Status = SeasonScheduled
+	s.CreatedAt = time.Now().UnixMilli()
+
+	query := `
+	INSERT INTO seasons (name, types, top_n, status, starts_at, ends_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+	return r.Dialect.wrap(r.DB).QueryRowContext(ctx, query, s.Name, string(types), s.TopN, s.Status,
+		s.StartsAt, s.EndsAt, s.CreatedAt).Scan(&s.ID)
+}
+
+// GetSeason mengambil satu season. nil kalau tidak ada.
+func (r *SeasonRepository) GetSeason(ctx context.Context, id int64) (*Season, error) {
+	seasons, err := r.querySeasons(ctx, " WHERE id = ?", id)
+	if err != nil || len(seasons) == 0 {
+		return nil, err
+	}
+	return &seasons[0], nil
+}
+
+// ListSeasons mengambil semua season, terbaru dulu
+func (r *SeasonRepository) ListSeasons(ctx context.Context) ([]Season, error) {
+	return r.querySeasons(ctx, "")
+}
+
+func (r *SeasonRepository) querySeasons(ctx context.Context, where string, args ...interface{}) ([]Season, error) {
+	query := `
+	SELECT id, name, types, top_n, status, starts_at, ends_at, created_at, archived_at
+	FROM seasons` + where + ` ORDER BY starts_at DESC, id DESC`
+	rows, err := r.Dialect.wrap(r.ReadDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []Season{}
+	for rows.Next() {
+		var s Season
+		var types string
+		if err := rows.Scan(&s.ID, &s.Name, &types, &s.TopN, &s.Status, &s.StartsAt, &s.EndsAt, &s.CreatedAt, &s.ArchivedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(types), &s.Types)
+		seasons = append(seasons, s)
+	}
+	return seasons, rows.Err()
+}
+
+// StartSeason mencatat baseline semua user untuk setiap type season lalu
+// mengubah statusnya menjadi active
+func (r *SeasonRepository) StartSeason(ctx context.Context, s *Season) error {
+	if err := r.flush(ctx); err != nil {
+		return err
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := r.Dialect.wrap(tx)
+
+	if _, err := q.ExecContext(ctx, "DELETE FROM season_baselines WHERE season_id = ?", s.ID); err != nil {
+		return err
+	}
+	for _, lbType := range s.Types {
+		expr, err := leaderboardExpr(r.Dialect, lbType)
+		if err != nil {
+			return err
+		}
+		query := "INSERT INTO season_baselines (season_id, type, user_id, value) SELECT ?, ?, id, " + expr + " FROM users"
+		if _, err := q.ExecContext(ctx, query, s.ID, lbType); err != nil {
+			return err
+		}
+	}
+	if _, err := q.ExecContext(ctx, "UPDATE seasons SET status = ? WHERE id = ?", SeasonActive, s.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.Status = SeasonActive
+	return nil
+}
+
+// ArchiveSeason menyimpan TopN peringkat total dan gained setiap type,
+// menghapus baseline, lalu mengubah status season menjadi ended
+func (r *SeasonRepository) ArchiveSeason(ctx context.Context, s *Season) error {
+	if err := r.flush(ctx); err != nil {
+		return err
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := r.Dialect.wrap(tx)
+
+	if _, err := q.ExecContext(ctx, "DELETE FROM season_results WHERE season_id = ?", s.ID); err != nil {
+		return err
+	}
+	for _, lbType := range s.Types {
+		expr, err := leaderboardExpr(r.Dialect, lbType)
+		if err != nil {
+			return err
+		}
+		total, err := queryLeaderboard(ctx, q, "SELECT id, "+expr+" AS value FROM users ORDER BY "+expr+" DESC, id DESC LIMIT ?", "DESC", s.TopN)
+		if err != nil {
+			return err
+		}
+		gained, err := r.gained(ctx, q, s.ID, lbType, expr, s.TopN, 0)
+		if err != nil {
+			return err
+		}
+		for kind, entries := range map[string][]LeaderboardEntry{SeasonTotal: total, SeasonGained: gained} {
+			for i, e := range entries {
+				query := `
+				INSERT INTO season_results (season_id, type, kind, rank, user_id, username, value)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`
+				if _, err := q.ExecContext(ctx, query, s.ID, lbType, kind, i+1, e.User.ID, e.User.Username, e.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	if _, err := q.ExecContext(ctx, "DELETE FROM season_baselines WHERE season_id = ?", s.ID); err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, "UPDATE seasons SET status = ?, archived_at = ? WHERE id = ?", SeasonEnded, now, s.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.Status = SeasonEnded
+	s.ArchivedAt = now
+	return nil
+}
+
+// GetSeasonGained mengambil peringkat kenaikan lbType sejak season mulai
+// (season harus active, baseline belum dihapus)
+func (r *SeasonRepository) GetSeasonGained(ctx context.Context, seasonID int64, lbType string, limit, offset int) ([]LeaderboardEntry, error) {
+	expr, err := leaderboardExpr(r.Dialect, lbType)
+	if err != nil {
+		return nil, err
+	}
+	return r.gained(ctx, r.Dialect.wrap(r.ReadDB), seasonID, lbType, expr, limit, offset)
+}
+
+// gained: user tanpa baseline (daftar setelah season mulai) dihitung dari
+// nilai default field-nya
+func (r *SeasonRepository) gained(ctx context.Context, q dbtx, seasonID int64, lbType, expr string, limit, offset int) ([]LeaderboardEntry, error) {
+	inner := `
+	SELECT users.id AS id, ` + expr + ` - COALESCE(b.value, ?) AS value
+	FROM users
+	LEFT JOIN season_baselines b ON b.season_id = ? AND b.type = ? AND b.user_id = users.id
+	ORDER BY 2 DESC, users.id DESC
+	LIMIT ? OFFSET ?`
+	return queryLeaderboard(ctx, q, inner, "DESC", seasonBaselineDefault(lbType), seasonID, lbType, limit, offset)
+}
+
+// GetSeasonResults mengambil hasil akhir season yang sudah diarsip
+func (r *SeasonRepository) GetSeasonResults(ctx context.Context, seasonID int64, lbType, kind string, limit, offset int) ([]SeasonResult, error) {
+	query := `
+	SELECT rank, user_id, COALESCE(username, ''), value
+	FROM season_results
+	WHERE season_id = ? AND type = ? AND kind = ?
+	ORDER BY rank
+	LIMIT ? OFFSET ?`
+	rows, err := r.Dialect.wrap(r.ReadDB).QueryContext(ctx, query, seasonID, lbType, kind, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SeasonResult{}
+	for rows.Next() {
+		var res SeasonResult
+		if err := rows.Scan(&res.Rank, &res.UserID, &res.Username, &res.Value); err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// seasonBaselineDefault adalah baseline user yang belum ada saat season mulai
+func seasonBaselineDefault(lbType string) float64 {
+	data, _ := json.Marshal(entity.GetDefaultUserMap())
+	return leaderboardValue(string(data), lbType)
+}
@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"Berpg/internal/cache"
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"testing"
+)
+
+func TestSeasonRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	users := repository.NewUserRepository(db, cache.NoopCache{}, repository.SQLite)
+	seasons := repository.NewSeasonRepository(db, repository.SQLite)
+
+	setMoney := func(id string, money float64) {
+		t.Helper()
+		user, version, _ := users.GetUser(ctx, id)
+		if user == nil {
+			user = entity.NewUser()
+			user.ID = id
+		}
+		user.Money = money
+		if _, err := users.SaveUser(ctx, id, user, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setMoney("a", 1000)
+	setMoney("b", 100)
+
+	season := &repository.Season{Name: "S1", Types: []string{"money"}, TopN: 10, StartsAt: 1, EndsAt: 2}
+	if err := seasons.CreateSeason(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	if err := seasons.StartSeason(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	setMoney("b", 600)
+	setMoney("c", entity.NewUser().Money+50)
+
+	gained, err := seasons.GetSeasonGained(ctx, season.ID, "money", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id    string
+		value float64
+	}{{"b", 500}, {"c", 50}, {"a", 0}}
+	if len(gained) != len(want) {
+		t.Fatalf("gained = %v", gained)
+	}
+	for i, w := range want {
+		if gained[i].User.ID != w.id || gained[i].Value != w.value {
+			t.Errorf("peringkat %d = %s %v, mau %s %v", i+1, gained[i].User.ID, gained[i].Value, w.id, w.value)
+		}
+	}
+
+	if err := seasons.ArchiveSeason(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	setMoney("b", 0)
+	results, err := seasons.GetSeasonResults(ctx, season.ID, "money", repository.SeasonGained, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season.Status != repository.SeasonEnded || len(results) != 3 || results[0].UserID != "b" || results[0].Value != 500 {
+		t.Errorf("status %s, hasil arsip = %v", season.Status, results)
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserSnapshot adalah salinan lengkap dokumen user setelah satu kali penulisan
+type UserSnapshot struct {
+	UserID    string       `json:"userId"`
+	Version   int64        `json:"version"`
+	User      *entity.User `json:"data"`
+	CreatedAt int64        `json:"createdAt"`
+}
+
+func recordSnapshot(ctx context.Context, q dbtx, userID string, version int64, dataJSON string) error {
+	query := `
+	INSERT INTO user_snapshots (user_id, version, data, created_at)
+	VALUES (?, ?, ?, ?)`
+	_, err := q.ExecContext(ctx, query, userID, version, dataJSON, time.Now().UnixMilli())
+	return err
+}
+
+// GetSnapshotAt mengambil snapshot terakhir yang dibuat pada atau sebelum at
+// (ms). nil kalau tidak ada.
+func (r *UserRepository) GetSnapshotAt(ctx context.Context, userID string, at int64) (*UserSnapshot, error) {
+	query := `
+	SELECT version, data, created_at FROM user_snapshots
+	WHERE user_id = ? AND created_at <= ?
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1`
+
+	snap := UserSnapshot{UserID: userID}
+	var dataJSON string
+	err := r.conn(r.ReadDB).QueryRowContext(ctx, query, userID, at).Scan(&snap.Version, &dataJSON, &snap.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snap.User = decodeUserDoc(userID, dataJSON)
+	return &snap, nil
+}
+
+// PruneSnapshots menghapus snapshot yang lebih tua dari before, tapi selalu
+// menyisakan snapshot terbaru tiap user
+func (r *UserRepository) PruneSnapshots(before time.Time) (int64, error) {
+	query := `
+	DELETE FROM user_snapshots
+	WHERE created_at < ?
+	AND id NOT IN (SELECT MAX(id) FROM user_snapshots GROUP BY user_id)`
+	res, err := r.conn(r.DB).ExecContext(context.Background(), query, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
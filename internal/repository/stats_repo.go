@@ -6,27 +6,16 @@ import (
 	"time"
 )
 
+// StatsRepository adalah StatsStore di atas database SQL
 type StatsRepository struct {
-	DB *sql.DB
+	DB      *sql.DB // penulis
+	ReadDB  *sql.DB
+	Dialect Dialect
 }
 
-func NewStatsRepository(db *sql.DB) *StatsRepository {
-	// Table creation (sama seperti sebelumnya)
-	query := `
-	CREATE TABLE IF NOT EXISTS traffic_stats (
-		timestamp INTEGER PRIMARY KEY,
-		get_count INTEGER DEFAULT 0,
-		post_count INTEGER DEFAULT 0,
-		put_count INTEGER DEFAULT 0,
-		delete_count INTEGER DEFAULT 0
-	);
-	CREATE INDEX IF NOT EXISTS idx_ts ON traffic_stats(timestamp);
-	`
-	_, err := db.Exec(query)
-	if err != nil {
-		panic(err)
-	}
-	return &StatsRepository{DB: db}
+// NewStatsRepository butuh tabel traffic_stats dari internal/migration
+func NewStatsRepository(db *DB, dialect Dialect) *StatsRepository {
+	return &StatsRepository{DB: db.Write, ReadDB: db.Read, Dialect: dialect}
 }
 
 // LogTraffic (Sama seperti sebelumnya)
@@ -52,13 +41,13 @@ func (r *StatsRepository) LogTraffic(method string) {
 	INSERT INTO traffic_stats (timestamp, get_count, post_count, put_count, delete_count)
 	VALUES (?, ?, ?, ?, ?)
 	ON CONFLICT(timestamp) DO UPDATE SET
-		get_count = get_count + excluded.get_count,
-		post_count = post_count + excluded.post_count,
-		put_count = put_count + excluded.put_count,
-		delete_count = delete_count + excluded.delete_count;
+		get_count = traffic_stats.get_count + excluded.get_count,
+		post_count = traffic_stats.post_count + excluded.post_count,
+		put_count = traffic_stats.put_count + excluded.put_count,
+		delete_count = traffic_stats.delete_count + excluded.delete_count;
 	`
 	go func() {
-		r.DB.Exec(query, ts, incGet, incPost, incPut, incDel)
+		r.DB.Exec(r.Dialect.Rebind(query), ts, incGet, incPost, incPut, incDel)
 	}()
 }
 
@@ -77,7 +66,7 @@ func (r *StatsRepository) GetHourlyStats(ctx context.Context) ([]map[string]inte
 		WHERE timestamp >= ? 
 		ORDER BY timestamp ASC`
 
-	rows, err := r.DB.QueryContext(ctx, query, startTs)
+	rows, err := r.ReadDB.QueryContext(ctx, r.Dialect.Rebind(query), startTs)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"time"
+)
+
+// UserStore adalah penyimpanan dokumen user beserta ledger, audit log,
+// snapshot, dan progress backfill. Implementasi: UserRepository (SQLite /
+// PostgreSQL), WriteBehindRepository di atasnya, dan MemoryUserRepository.
+type UserStore interface {
+	GetUser(ctx context.Context, userID string) (*entity.User, int64, error)
+	SaveUser(ctx context.Context, userID string, user *entity.User, expectedVersion int64) (int64, error)
+	MutateUser(ctx context.Context, userID string, fn func(user *entity.User) error) (*entity.User, int64, error)
+	MutateUsers(ctx context.Context, userIDs []string, fn func(users map[string]*entity.User) error, transfers []Transfer) (map[string]*entity.User, error)
+	GetAFKUsers(ctx context.Context) (map[string]*entity.User, error)
+	GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardEntry, error)
+	GetLeaderboardPosition(ctx context.Context, lbType string, asc bool, userID string) (*LeaderboardCursor, int, error)
+	CountUsers(ctx context.Context) (int, error)
+
+	GetHistory(ctx context.Context, userID, field string, limit int, cursor int64) ([]LedgerEntry, int64, error)
+	GetAuditLog(ctx context.Context, userID string, since int64, limit int) ([]AuditEntry, error)
+	GetSnapshotAt(ctx context.Context, userID string, at int64) (*UserSnapshot, error)
+	PruneSnapshots(before time.Time) (int64, error)
+
+	CreateBackfillJob(ctx context.Context, actor string) (*BackfillJob, error)
+	GetLatestBackfillJob(ctx context.Context) (*BackfillJob, error)
+	SaveBackfillJob(ctx context.Context, job *BackfillJob) error
+	GetUserIDsAfter(ctx context.Context, cursor string, limit int) ([]string, error)
+}
+
+// StatsStore menyimpan jumlah request per menit untuk GET /stats
+type StatsStore interface {
+	LogTraffic(method string)
+	GetHourlyStats(ctx context.Context) ([]map[string]interface{}, error)
+	ResetStats() error
+}
+
+// SeasonStore menyimpan season leaderboard, baseline, dan hasil akhirnya.
+// Implementasi: SeasonRepository dan MemorySeasonRepository.
+type SeasonStore interface {
+	CreateSeason(ctx context.Context, s *Season) error
+	GetSeason(ctx context.Context, id int64) (*Season, error)
+	ListSeasons(ctx context.Context) ([]Season, error)
+	StartSeason(ctx context.Context, s *Season) error
+	ArchiveSeason(ctx context.Context, s *Season) error
+	GetSeasonGained(ctx context.Context, seasonID int64, lbType string, limit, offset int) ([]LeaderboardEntry, error)
+	GetSeasonResults(ctx context.Context, seasonID int64, lbType, kind string, limit, offset int) ([]SeasonResult, error)
+}
+
+var (
+	_ UserStore  = (*UserRepository)(nil)
+	_ UserStore  = (*MemoryUserRepository)(nil)
+	_ UserStore  = (*WriteBehindRepository)(nil)
+	_ StatsStore = (*StatsRepository)(nil)
+	_ StatsStore = (*MemoryStatsRepository)(nil)
+
+	_ SeasonStore = (*SeasonRepository)(nil)
+	_ SeasonStore = (*MemorySeasonRepository)(nil)
+)
@@ -0,0 +1,138 @@
+package repository_test
+
+import (
+	"Berpg/internal/cache"
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestUserStore menjalankan skenario yang sama di setiap backend UserStore
+func TestUserStore(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) repository.UserStore
+	}{
+		{"memory", func(t *testing.T) repository.UserStore {
+			return repository.NewMemoryUserRepository()
+		}},
+		{"sqlite", func(t *testing.T) repository.UserStore {
+			return repository.NewUserRepository(openTestDB(t), cache.NewLRUCache(100), repository.SQLite)
+		}},
+		{"postgres", func(t *testing.T) repository.UserStore {
+			return repository.NewUserRepository(openPostgres(t), cache.NewLRUCache(100), repository.Postgres)
+		}},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			testUserStore(t, b.open(t))
+		})
+	}
+}
+
+func testUserStore(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	// DB PostgreSQL test bisa dipakai berulang, jadi id user dibuat unik
+	prefix := strconv.FormatInt(time.Now().UnixNano(), 36) + "-"
+	a, b := prefix+"a", prefix+"b"
+
+	user, version, err := store.GetUser(ctx, a)
+	if err != nil || user != nil || version != 0 {
+		t.Fatalf("user belum ada = %v, %d, %v", user, version, err)
+	}
+
+	newUser := func(money float64) *entity.User {
+		u := entity.NewUser()
+		u.Money = money
+		return u
+	}
+	saves := []struct {
+		name        string
+		userID      string
+		money       float64
+		version     int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{"user baru", a, 100, 0, 1, nil},
+		{"user baru lain", b, 0, 0, 1, nil},
+		{"user baru yang sudah ada", a, 1, 0, 0, repository.ErrVersionConflict},
+		{"versi basi", a, 1, 5, 0, repository.ErrVersionConflict},
+		{"versi cocok", a, 200, 1, 2, nil},
+		{"tanpa cek versi", a, 300, repository.AnyVersion, 3, nil},
+	}
+	for _, tt := range saves {
+		got, err := store.SaveUser(ctx, tt.userID, newUser(tt.money), tt.version)
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+			t.Fatalf("%s: err = %v, mau %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.wantVersion {
+			t.Errorf("%s: versi = %d, mau %d", tt.name, got, tt.wantVersion)
+		}
+	}
+
+	addMoney := func(amount float64) func(u *entity.User) error {
+		return func(u *entity.User) error {
+			u.Money += amount
+			return nil
+		}
+	}
+	mutates := []struct {
+		name    string
+		ctx     context.Context
+		userID  string
+		wantErr error
+	}{
+		{"mutate", ctx, a, nil},
+		{"If-Match basi", repository.WithExpectedVersion(ctx, 1), a, repository.ErrVersionConflict},
+		{"If-Match cocok", repository.WithExpectedVersion(ctx, 4), a, nil},
+		{"user tidak ada", ctx, prefix + "x", repository.ErrUserNotFound},
+	}
+	for _, tt := range mutates {
+		_, _, err := store.MutateUser(tt.ctx, tt.userID, addMoney(50))
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+			t.Fatalf("%s: err = %v, mau %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	transfer := repository.Transfer{FromID: a, ToID: b, Field: "money", Amount: 25}
+	_, err = store.MutateUsers(repository.WithReason(ctx, "transfer"), []string{a, b}, func(users map[string]*entity.User) error {
+		users[a].Money -= 25
+		users[b].Money += 25
+		return nil
+	}, []repository.Transfer{transfer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, version, err = store.GetUser(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Money != 300+50+50-25 || version != 6 {
+		t.Errorf("money = %v versi = %d, mau 375 dan 6", user.Money, version)
+	}
+	if other, _, _ := store.GetUser(ctx, b); other.Money != 25 {
+		t.Errorf("money penerima = %v, mau 25", other.Money)
+	}
+
+	history, _, err := store.GetHistory(ctx, a, "money", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) == 0 || history[0].Delta != -25 || history[0].BalanceAfter != 375 || history[0].Reason != "transfer" {
+		t.Errorf("ledger terakhir = %+v", history)
+	}
+
+	snapshot, err := store.GetSnapshotAt(ctx, a, time.Now().UnixMilli())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Version != 6 {
+		t.Errorf("snapshot terakhir versi %d, mau 6", snapshot.Version)
+	}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Transfer adalah catatan perpindahan nilai (money, diamond, item) antar user
+type Transfer struct {
+	FromID string  `json:"from"`
+	ToID   string  `json:"to"`
+	Field  string  `json:"field"`
+	Amount float64 `json:"amount"`
+	Note   string  `json:"note,omitempty"`
+}
+
+func insertTransfer(ctx context.Context, q dbtx, t Transfer) error {
+	query := `
+	INSERT INTO transfers (from_id, to_id, field, amount, note, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := q.ExecContext(ctx, query, t.FromID, t.ToID, t.Field, t.Amount, t.Note, time.Now().UnixMilli())
+	return err
+}
@@ -1,135 +1,551 @@
 package repository
 
 import (
+	"Berpg/internal/cache"
+	"Berpg/internal/entity"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
+// AnyVersion dipakai sebagai expectedVersion kalau penulisan tidak perlu
+// dicek versinya (overwrite biasa).
+const AnyVersion int64 = -1
+
+// ErrVersionConflict dikembalikan SaveUser kalau versi dokumen di DB sudah
+// berbeda dengan versi yang diharapkan (ada penulis lain yang lebih dulu).
+var ErrVersionConflict = errors.New("user version conflict")
+
+// ErrUserNotFound dikembalikan operasi yang butuh user sudah tersimpan
+var ErrUserNotFound = errors.New("user not found")
+
+// UserRepository adalah UserStore di atas database SQL (SQLite atau
+// PostgreSQL, lihat Dialect) dengan cache di depannya (Redis, memori, atau
+// tanpa cache; lihat internal/cache). Error cache tidak pernah menggagalkan
+// operasi, database tetap sumber kebenaran.
 type UserRepository struct {
-	DB    *sql.DB
-	Redis *redis.Client
+	DB      *sql.DB // penulis (transaksi)
+	ReadDB  *sql.DB // pembacaan biasa, lihat repository.DB
+	Cache   cache.Cache
+	Dialect Dialect
+
+	// loads menggabungkan pembacaan database untuk user yang sama saat cache
+	// miss, supaya ratusan request serentak cukup satu query
+	loads singleflight.Group
+
+	// loading adalah pembacaan loads yang sedang berjalan, supaya penulisan
+	// yang commit di tengahnya bisa mencegah hasil basi masuk cache
+	loadingMu sync.Mutex
+	loading   map[string]*userLoad
 }
 
-func NewUserRepository(db *sql.DB, rdb *redis.Client) *UserRepository {
+// userLoad adalah satu pembacaan database GetUser yang sedang berjalan.
+// stale diisi kalau ada penulisan user itu yang commit selama pembacaan.
+type userLoad struct {
+	mu    sync.Mutex
+	stale bool
+}
 
-	query := `
-	CREATE TABLE IF NOT EXISTS users (
-		id TEXT PRIMARY KEY,
-		username TEXT,
-		money REAL DEFAULT 0,
-		level INTEGER DEFAULT 0,
-		data TEXT
-	);
-	CREATE INDEX IF NOT EXISTS idx_money ON users(money);
-	CREATE INDEX IF NOT EXISTS idx_level ON users(level);
-	`
-	_, err := db.Exec(query)
+const (
+	// TTL entry user:<id> di cache, ditambah jitter sampai userCacheJitter
+	// supaya entry yang dibuat bersamaan tidak kedaluwarsa bersamaan
+	userCacheTTL    = 10 * time.Minute
+	userCacheJitter = 2 * time.Minute
+
+	// missingUserTTL: user yang tidak ada juga di-cache sebentar. Saat user
+	// dibuat, entry ini langsung ditimpa SaveUser.
+	missingUserTTL = 30 * time.Second
+)
+
+// missingUser adalah isi key user:<id> untuk user yang tidak ada
+const missingUser = "missing"
+
+// cachedUser adalah isi key user:<id> di cache (data + versi dokumen)
+type cachedUser struct {
+	Version int64           `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewUserRepository butuh tabel yang sudah dibuat oleh internal/migration
+func NewUserRepository(db *DB, c cache.Cache, dialect Dialect) *UserRepository {
+	return &UserRepository{DB: db.Write, ReadDB: db.Read, Cache: c, Dialect: dialect}
+}
+
+// conn membungkus *sql.DB / *sql.Tx sesuai dialect repository
+func (r *UserRepository) conn(q dbtx) dbtx {
+	return r.Dialect.wrap(q)
+}
+
+// GetUser mengambil user + versi dokumen (Cek cache -> Cek database).
+// Key default yang belum ada di dokumen terisi nilai default (lihat
+// entity.User.MissingFields). Kalau user tidak ada, user nil dan versi 0.
+func (r *UserRepository) GetUser(ctx context.Context, userID string) (*entity.User, int64, error) {
+	//Cek cache
+	val, ok, _ := r.Cache.Get(ctx, "user:"+userID)
+	if ok {
+		if val == missingUser {
+			return nil, 0, nil
+		}
+		var cached cachedUser
+		// Entry format lama (tanpa versi) dianggap cache miss
+		if json.Unmarshal([]byte(val), &cached) == nil && cached.Version > 0 {
+			return decodeUserDoc(userID, string(cached.Data)), cached.Version, nil
+		}
+	}
+
+	// Cek database, satu query untuk semua request user yang sama. Hasilnya
+	// JSON, jadi tiap pemanggil decode dokumennya sendiri (tidak berbagi
+	// pointer). Context tidak ikut dibatalkan kalau request pertama batal.
+	res, err, _ := r.loads.Do(userID, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		load := r.startLoad(userID)
+		defer r.endLoad(userID)
+
+		dataJSON, version, err := r.loadUser(loadCtx, r.conn(r.ReadDB), userID, false)
+		if err == sql.ErrNoRows {
+			r.cacheLoaded(loadCtx, load, userID, missingUser, 0)
+			return cachedUser{}, nil // Not found
+		} else if err != nil {
+			return nil, err
+		}
+
+		// 3. Simpan ke cache (Cache aside)
+		r.cacheLoaded(loadCtx, load, userID, dataJSON, version)
+		return cachedUser{Version: version, Data: json.RawMessage(dataJSON)}, nil
+	})
 	if err != nil {
-		panic(err)
+		return nil, 0, err
 	}
-	return &UserRepository{DB: db, Redis: rdb}
+
+	loaded := res.(cachedUser)
+	if loaded.Version == 0 {
+		return nil, 0, nil
+	}
+	return decodeUserDoc(userID, string(loaded.Data)), loaded.Version, nil
 }
 
-// GetUser mengambil data (Cek Redis -> Cek SQLite)
-func (r *UserRepository) GetUser(ctx context.Context, userID string) (map[string]interface{}, error) {
-	//Cek Redis
-	val, err := r.Redis.Get(ctx, "user:"+userID).Result()
+// SaveUser menyimpan user dan mengembalikan versi baru dokumen.
+// Kalau expectedVersion bukan AnyVersion, penulisan hanya terjadi jika versi
+// di DB masih sama (optimistic concurrency); selain itu ErrVersionConflict.
+func (r *UserRepository) SaveUser(ctx context.Context, userID string, user *entity.User, expectedVersion int64) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Dokumen lama dibutuhkan untuk mencatat perubahan saldo di ledger
+	q := r.conn(tx)
+	var old map[string]interface{}
+	oldJSON, _, err := r.loadUser(ctx, q, userID, true)
 	if err == nil {
-		var data map[string]interface{}
-		json.Unmarshal([]byte(val), &data)
-		return data, nil
+		old = decodeUser(oldJSON)
+	} else if err != sql.ErrNoRows {
+		return 0, err
 	}
 
-	// Cek SQLite
-	var dataJSON string
-	err = r.DB.QueryRowContext(ctx, "SELECT data FROM users WHERE id = ?", userID).Scan(&dataJSON)
+	dataStr, newVersion, err := r.writeUser(ctx, q, userID, old, user, expectedVersion)
+	if err == ErrVersionConflict {
+		// Cache kemungkinan basi, buang supaya pembacaan berikutnya ambil dari database
+		r.uncacheWritten(ctx, userID)
+		return 0, err
+	} else if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Update cache langsung biar sinkron
+	r.cacheWritten(ctx, userID, dataStr, newVersion)
+	return newVersion, nil
+}
+
+// MutateUser membaca user, menjalankan fn, lalu menyimpan hasilnya dalam
+// satu transaksi SQLite, jadi tidak ada penulis lain yang bisa menyela.
+// fn boleh mengubah user langsung; kalau fn mengembalikan error, transaksi
+// dibatalkan dan tidak ada yang ditulis. fn tidak boleh memanggil method
+// repository lain (koneksi SQLite cuma satu).
+func (r *UserRepository) MutateUser(ctx context.Context, userID string, fn func(user *entity.User) error) (*entity.User, int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	q := r.conn(tx)
+	dataJSON, version, err := r.loadUser(ctx, q, userID, true)
 	if err == sql.ErrNoRows {
-		return nil, nil // Not found
+		return nil, 0, ErrUserNotFound
 	} else if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	if err := checkExpectedVersion(ctx, version); err != nil {
+		return nil, 0, err
 	}
 
-	var data map[string]interface{}
-	json.Unmarshal([]byte(dataJSON), &data)
+	user := decodeUserDoc(userID, dataJSON)
+	if err := fn(user); err != nil {
+		return nil, 0, err
+	}
 
-	// 3. Simpan ke Redis (Cache aside)
-	r.Redis.Set(ctx, "user:"+userID, dataJSON, 10*time.Minute)
+	dataStr, newVersion, err := r.writeUser(ctx, q, userID, decodeUser(dataJSON), user, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
 
-	return data, nil
+	r.cacheWritten(ctx, userID, dataStr, newVersion)
+	return user, newVersion, nil
 }
 
-// SaveUser menyimpan data
-func (r *UserRepository) SaveUser(ctx context.Context, userID string, data map[string]interface{}) error {
-	dataBytes, _ := json.Marshal(data)
-	dataStr := string(dataBytes)
+// MutateUsers seperti MutateUser tapi untuk beberapa user sekaligus dalam satu
+// transaksi (transfer antar pemain). Semua user harus sudah ada. transfers
+// dicatat di tabel transfers dalam transaksi yang sama. Setelah commit, key
+// user:<id> di cache dihapus supaya pembacaan berikutnya ambil dari database.
+func (r *UserRepository) MutateUsers(ctx context.Context, userIDs []string, fn func(users map[string]*entity.User) error, transfers []Transfer) (map[string]*entity.User, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Baris dikunci urut id supaya dua transaksi tidak saling menunggu (PostgreSQL)
+	q := r.conn(tx)
+	sortedIDs := append([]string{}, userIDs...)
+	sort.Strings(sortedIDs)
+
+	olds := make(map[string]map[string]interface{}, len(userIDs))
+	users := make(map[string]*entity.User, len(userIDs))
+	versions := make(map[string]int64, len(userIDs))
+	for _, userID := range sortedIDs {
+		if _, loaded := users[userID]; loaded {
+			continue
+		}
 
-	username, _ := data["username"].(string)
-	money, _ := data["money"].(float64)
+		dataJSON, version, err := r.loadUser(ctx, q, userID, true)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+		} else if err != nil {
+			return nil, err
+		}
+
+		olds[userID] = decodeUser(dataJSON)
+		users[userID] = decodeUserDoc(userID, dataJSON)
+		versions[userID] = version
+	}
 
-	// Ambil level dari nested rpg
-	level := 0
-	if rpg, ok := data["rpg"].(map[string]interface{}); ok {
-		if l, ok := rpg["level"].(float64); ok {
-			level = int(l)
+	if err := fn(users); err != nil {
+		return nil, err
+	}
+
+	for userID, user := range users {
+		if _, _, err := r.writeUser(ctx, q, userID, olds[userID], user, versions[userID]); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range transfers {
+		if err := insertTransfer(ctx, q, t); err != nil {
+			return nil, err
 		}
 	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
-	// Upsert ke SQLite
-	query := `
-	INSERT INTO users (id, username, money, level, data)
-	VALUES (?, ?, ?, ?, ?)
-	ON CONFLICT(id) DO UPDATE SET
-		username=excluded.username,
-		money=excluded.money,
-		level=excluded.level,
-		data=excluded.data;
-	`
-	_, err := r.DB.ExecContext(ctx, query, userID, username, money, level, dataStr)
+	for userID := range users {
+		r.uncacheWritten(ctx, userID)
+	}
+	return users, nil
+}
+
+// PendingWrite adalah satu penulisan dokumen yang ditunda (lihat
+// WriteBehindRepository). Keterangan penulisan disimpan karena context
+// request asal sudah selesai saat penulisan benar-benar dijalankan.
+type PendingWrite struct {
+	UserID          string
+	Data            string // JSON dokumen
+	ExpectedVersion int64  // versi sebelum penulisan ini, 0 untuk user baru
+	Base            string // JSON dokumen sebelum penulisan ini ("" untuk user baru), untuk rebase
+	Reason          string
+	Actor           string
+	RequestID       string
+	QueuedAt        time.Time
+
+	attempts int                                    // flush yang gagal karena penulisan ini
+	ifMatch  bool                                   // bersyarat versi, lihat WithExpectedVersion
+	check    func(doc map[string]interface{}) error // lihat WithRebaseCheck
+}
+
+// BatchWriteError menandai penulisan mana (index di writes) yang membuat
+// SaveUsers gagal
+type BatchWriteError struct {
+	UserID string
+	Index  int
+	Err    error
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("gagal menulis user %s: %v", e.UserID, e.Err)
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
+
+// SaveUsers menjalankan writes berurutan dalam satu transaksi; ledger, audit,
+// dan snapshot tetap dicatat per penulisan. Kalau satu penulisan gagal, semua
+// dibatalkan dan errornya *BatchWriteError.
+func (r *UserRepository) SaveUsers(ctx context.Context, writes []PendingWrite) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Update Redis langsung biar sinkron
-	r.Redis.Set(ctx, "user:"+userID, dataStr, 10*time.Minute)
+	q := r.conn(tx)
+	stored := make(map[string]cachedUser, len(writes))
+	for i, w := range writes {
+		var old map[string]interface{}
+		oldJSON, _, err := r.loadUser(ctx, q, w.UserID, true)
+		if err == nil {
+			old = decodeUser(oldJSON)
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		writeCtx := WithRequestID(WithActor(WithReason(ctx, w.Reason), w.Actor), w.RequestID)
+		user := decodeUserDoc(w.UserID, w.Data)
+		dataStr, newVersion, err := r.writeUser(writeCtx, q, w.UserID, old, user, w.ExpectedVersion)
+		if err != nil {
+			return &BatchWriteError{UserID: w.UserID, Index: i, Err: err}
+		}
+		stored[w.UserID] = cachedUser{Version: newVersion, Data: json.RawMessage(dataStr)}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for userID, c := range stored {
+		r.cacheWritten(ctx, userID, string(c.Data), c.Version)
+	}
 	return nil
 }
 
-// GetAllUsers untuk Leaderboard
-func (r *UserRepository) GetAllUsers(ctx context.Context) ([]map[string]interface{}, error) {
-	rows, err := r.DB.QueryContext(ctx, "SELECT data FROM users")
+// loadUser membaca JSON dokumen + versi. sql.ErrNoRows kalau user tidak ada.
+// forUpdate mengunci baris sampai transaksi selesai (PostgreSQL; SQLite
+// sudah terkunci karena cuma ada satu koneksi penulis).
+func (r *UserRepository) loadUser(ctx context.Context, q dbtx, userID string, forUpdate bool) (string, int64, error) {
+	query := "SELECT data, version FROM users WHERE id = ?"
+	if forUpdate && r.Dialect == Postgres {
+		query += " FOR UPDATE"
+	}
+
+	var dataJSON string
+	var version int64
+	err := q.QueryRowContext(ctx, query, userID).Scan(&dataJSON, &version)
+	return dataJSON, version, err
+}
+
+// decodeUser mengubah JSON tersimpan menjadi map apa adanya (untuk ledger,
+// audit, dan diff)
+func decodeUser(dataJSON string) map[string]interface{} {
+	var data map[string]interface{}
+	json.Unmarshal([]byte(dataJSON), &data)
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	return data
+}
+
+// decodeUserDoc mengubah JSON tersimpan menjadi entity.User di atas nilai
+// default. Field lama yang tipenya salah disimpan apa adanya di Extra (dicatat
+// di log, lihat entity.TypeMismatchError) supaya dokumen lama masih bisa
+// dibaca tanpa nilainya tertimpa default saat ditulis ulang.
+func decodeUserDoc(userID, dataJSON string) *entity.User {
+	user := entity.NewUser()
+	if err := json.Unmarshal([]byte(dataJSON), user); err != nil {
+		slog.Warn("Dokumen user tidak sesuai tipe", "userId", userID, "err", err)
+	}
+	if user.ID == "" {
+		user.ID = userID
+	}
+	return user
+}
+
+// IndexColumns menghitung kolom index users di SQLite (username, money, level)
+// dari dokumen user. Dipakai juga oleh migration dokumen supaya hasilnya sama.
+func IndexColumns(user *entity.User) (username string, money float64, level int) {
+	return user.Username, user.Money, int(user.Rpg.Level)
+}
+
+// writeUser menulis dokumen ke tabel users (kolom index ikut dihitung ulang)
+// dan mengembalikan JSON yang tersimpan beserta versi barunya. old adalah
+// dokumen tersimpan sebelum diubah (nil untuk user baru), dipakai untuk
+// ledger dan audit log. Setiap penulisan juga menyimpan snapshot untuk restore.
+func (r *UserRepository) writeUser(ctx context.Context, q dbtx, userID string, old map[string]interface{}, user *entity.User, expectedVersion int64) (string, int64, error) {
+	dataBytes, err := json.Marshal(user)
 	if err != nil {
-		return nil, err
+		return "", 0, err
 	}
-	defer rows.Close()
+	dataStr := string(dataBytes)
 
-	var users []map[string]interface{}
-	for rows.Next() {
-		var dataStr string
-		if err := rows.Scan(&dataStr); err != nil {
-			continue
+	username, money, level := IndexColumns(user)
+
+	// Versi naik setiap kali tulis
+	var query string
+	var args []interface{}
+	switch {
+	case r.Dialect == Postgres:
+		query, args = postgresWriteQuery(userID, dataStr, expectedVersion)
+	case expectedVersion > 0:
+		// Hanya update kalau versi masih sama
+		query = `
+		UPDATE users SET username = ?, money = ?, level = ?, data = ?, version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version;
+		`
+		args = []interface{}{username, money, level, dataStr, userID, expectedVersion}
+	default:
+		// Upsert ke SQLite. expectedVersion 0 artinya user harus belum ada.
+		query = `
+		INSERT INTO users (id, username, money, level, data, version)
+		VALUES (?, ?, ?, ?, ?, 1)
+		ON CONFLICT(id) DO UPDATE SET
+			username=excluded.username,
+			money=excluded.money,
+			level=excluded.level,
+			data=excluded.data,
+			version=users.version + 1
+		WHERE ? < 0
+		RETURNING version;
+		`
+		args = []interface{}{userID, username, money, level, dataStr, expectedVersion}
+	}
+
+	var newVersion int64
+	err = q.QueryRowContext(ctx, query, args...).Scan(&newVersion)
+	if err == sql.ErrNoRows {
+		return "", 0, ErrVersionConflict
+	} else if err != nil {
+		return "", 0, err
+	}
+
+	// Bandingkan dalam bentuk map seperti yang tersimpan
+	stored := decodeUser(dataStr)
+	if err := recordLedger(ctx, q, userID, old, stored); err != nil {
+		return "", 0, err
+	}
+	if err := recordAudit(ctx, q, userID, old, stored); err != nil {
+		return "", 0, err
+	}
+	if err := recordSnapshot(ctx, q, userID, newVersion, dataStr); err != nil {
+		return "", 0, err
+	}
+	return dataStr, newVersion, nil
+}
+
+func (r *UserRepository) cacheUser(ctx context.Context, userID string, dataJSON string, version int64) {
+	cached, _ := json.Marshal(cachedUser{Version: version, Data: json.RawMessage(dataJSON)})
+	ttl := userCacheTTL + rand.N(userCacheJitter)
+	r.Cache.Set(ctx, "user:"+userID, string(cached), ttl)
+}
+
+// startLoad mendaftarkan pembacaan GetUser untuk userID (dipanggil di dalam
+// loads, jadi paling banyak satu per user)
+func (r *UserRepository) startLoad(userID string) *userLoad {
+	r.loadingMu.Lock()
+	defer r.loadingMu.Unlock()
+	if r.loading == nil {
+		r.loading = make(map[string]*userLoad)
+	}
+	load := &userLoad{}
+	r.loading[userID] = load
+	return load
+}
+
+func (r *UserRepository) endLoad(userID string) {
+	r.loadingMu.Lock()
+	delete(r.loading, userID)
+	r.loadingMu.Unlock()
+}
+
+// cacheLoaded menyimpan hasil pembacaan GetUser ke cache (dataJSON
+// missingUser dan version 0 untuk user yang tidak ada), kecuali ada
+// penulisan yang commit selama pembacaan, atau cache sudah berisi versi yang
+// sama/lebih baru (misal dari proses lain). Dicek dan disimpan sambil
+// memegang load.mu, jadi penulisan yang commit setelah pengecekan pasti
+// menimpa hasil ini (lihat markWritten).
+func (r *UserRepository) cacheLoaded(ctx context.Context, load *userLoad, userID, dataJSON string, version int64) {
+	load.mu.Lock()
+	defer load.mu.Unlock()
+	if load.stale {
+		return
+	}
+	if val, ok, _ := r.Cache.Get(ctx, "user:"+userID); ok && val != missingUser {
+		var cached cachedUser
+		if json.Unmarshal([]byte(val), &cached) == nil && cached.Version >= version {
+			return
 		}
-		var u map[string]interface{}
-		json.Unmarshal([]byte(dataStr), &u)
-		users = append(users, u)
 	}
-	return users, nil
+	if version == 0 {
+		r.Cache.Set(ctx, "user:"+userID, missingUser, missingUserTTL)
+		return
+	}
+	r.cacheUser(ctx, userID, dataJSON, version)
+}
+
+// markWritten menandai pembacaan GetUser userID yang sedang berjalan sebagai
+// basi. Harus dipanggil setelah commit dan sebelum cache diisi/dihapus.
+func (r *UserRepository) markWritten(userID string) {
+	r.loadingMu.Lock()
+	load := r.loading[userID]
+	r.loadingMu.Unlock()
+	if load != nil {
+		load.mu.Lock()
+		load.stale = true
+		load.mu.Unlock()
+	}
+}
+
+// cacheWritten mengisi cache dengan dokumen yang baru saja ditulis
+func (r *UserRepository) cacheWritten(ctx context.Context, userID string, dataJSON string, version int64) {
+	r.markWritten(userID)
+	r.cacheUser(ctx, userID, dataJSON, version)
+}
+
+// uncacheWritten menghapus entry cache user yang baru saja ditulis (atau
+// yang cache-nya ketahuan basi)
+func (r *UserRepository) uncacheWritten(ctx context.Context, userID string) {
+	r.markWritten(userID)
+	r.Cache.Del(ctx, "user:"+userID)
 }
 
 // get user AFK
-func (r *UserRepository) GetAFKUsers(ctx context.Context) (map[string]interface{}, error) {
+func (r *UserRepository) GetAFKUsers(ctx context.Context) (map[string]*entity.User, error) {
 	query := `SELECT id, data FROM users WHERE json_extract(data, '$.afk') > 0`
+	if r.Dialect == Postgres {
+		query = `SELECT id, data FROM users WHERE (data->>'afk')::double precision > 0`
+	}
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	rows, err := r.conn(r.ReadDB).QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[string]interface{})
+	result := make(map[string]*entity.User)
 
 	for rows.Next() {
 		var id string
@@ -138,11 +554,31 @@ func (r *UserRepository) GetAFKUsers(ctx context.Context) (map[string]interface{
 			continue
 		}
 
-		var userData map[string]interface{}
-		if err := json.Unmarshal([]byte(dataJSON), &userData); err == nil {
-			userData["id"] = id
-			result[id] = userData
-		}
+		user := decodeUserDoc(id, dataJSON)
+		user.ID = id
+		result[id] = user
 	}
 	return result, nil
 }
+
+// postgresWriteQuery sama seperti query SQLite di writeUser, tapi username,
+// money, dan level adalah generated column dari data (JSONB) di PostgreSQL
+func postgresWriteQuery(userID, dataStr string, expectedVersion int64) (string, []interface{}) {
+	if expectedVersion > 0 {
+		query := `
+		UPDATE users SET data = ?, version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version`
+		return query, []interface{}{dataStr, userID, expectedVersion}
+	}
+
+	query := `
+	INSERT INTO users (id, data, version)
+	VALUES (?, ?, 1)
+	ON CONFLICT(id) DO UPDATE SET
+		data = excluded.data,
+		version = users.version + 1
+	WHERE ? < 0
+	RETURNING version`
+	return query, []interface{}{userID, dataStr, expectedVersion}
+}
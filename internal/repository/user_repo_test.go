@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"Berpg/internal/cache"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// cacheLoaded tidak boleh menimpa penulisan yang commit selama pembacaan
+// GetUser, atau versi yang lebih baru di cache
+func TestCacheLoaded(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		cached      string // isi cache sebelum hasil pembacaan disimpan
+		written     bool   // ada penulisan selama pembacaan
+		data        string
+		version     int64
+		wantVersion int64 // 0 = tidak ada entry, -1 = missingUser
+	}{
+		{"cache kosong", "", false, `{"money":1}`, 2, 2},
+		{"ada penulisan selama pembacaan", "", true, `{"money":1}`, 2, 0},
+		{"cache lebih baru", `{"version":3,"data":{}}`, false, `{"money":1}`, 2, 3},
+		{"cache lebih lama", `{"version":1,"data":{}}`, false, `{"money":1}`, 2, 2},
+		{"user tidak ada", "", false, missingUser, 0, -1},
+		{"user tidak ada tapi baru dibuat", "", true, missingUser, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &UserRepository{Cache: cache.NewLRUCache(10)}
+			if tt.cached != "" {
+				r.Cache.Set(ctx, "user:u1", tt.cached, time.Minute)
+			}
+			load := r.startLoad("u1")
+			if tt.written {
+				r.markWritten("u1")
+			}
+			r.cacheLoaded(ctx, load, "u1", tt.data, tt.version)
+			r.endLoad("u1")
+
+			val, ok, _ := r.Cache.Get(ctx, "user:u1")
+			var got int64
+			switch {
+			case !ok:
+			case val == missingUser:
+				got = -1
+			default:
+				var cached cachedUser
+				json.Unmarshal([]byte(val), &cached)
+				got = cached.Version
+			}
+			if got != tt.wantVersion {
+				t.Errorf("versi di cache = %d, mau %d", got, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,660 @@
+package repository
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriteBehindRepository menunda penulisan dokumen user: SaveUser dan
+// MutateUser hanya mengantrikan dokumen di memori proses, lalu antrian
+// ditulis ke database dalam satu transaksi setiap Interval atau begitu ada
+// MaxBatch penulisan. Ledger, audit, dan snapshot tetap tercatat per
+// penulisan saat flush.
+//
+// Aturan versi tetap berlaku karena versi dihitung dari dokumen terbaru di
+// antrian (atau di database, bukan cache). Kalau ada penulisan dari luar
+// antrian, flush user itu gagal (version conflict) lalu antriannya di-rebase
+// di atas dokumen database (lihat rebaseDoc), jadi penulisan yang sudah
+// dijawab sukses tidak dibuang, kecuali syaratnya (If-Match, WithRebaseCheck,
+// schema) tidak lagi terpenuhi. Penulisan yang tetap gagal disimpan setelah
+// maxWriteAttempts kali flush dibuang ke dead letter (lihat DeadLetters)
+// supaya tidak menahan penulisan lain. Penulisan yang belum di-flush hilang
+// kalau proses mati mendadak, jadi Close harus dipanggil saat shutdown.
+//
+// Pembacaan massal (leaderboard, jumlah user) langsung ke database tanpa
+// flush, jadi bisa tertinggal paling lama sekitar Interval.
+type WriteBehindRepository struct {
+	*UserRepository
+
+	Interval time.Duration
+	MaxBatch int
+
+	locks   [64]sync.Mutex // dikunci per user (hash id) selama baca-ubah-antri
+	flushMu sync.Mutex
+
+	mu     sync.Mutex
+	queue  []PendingWrite
+	latest map[string]cachedUser // dokumen terbaru per user yang belum selesai di-flush
+	closed bool
+	stats  WriteBehindStats
+
+	deadLetters []DeadLetter
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WriteBehindStats untuk memantau antrian (GET /admin/write-behind)
+type WriteBehindStats struct {
+	Pending         int   `json:"pending"`
+	OldestPendingMs int64 `json:"oldestPendingMs"` // umur penulisan tertua yang belum tersimpan
+	LastFlushAt     int64 `json:"lastFlushAt"`
+	LastFlushSize   int   `json:"lastFlushSize"`
+	LastFlushLagMs  int64 `json:"lastFlushLagMs"` // jeda antri -> tersimpan untuk penulisan tertua di flush terakhir
+	MaxFlushLagMs   int64 `json:"maxFlushLagMs"`
+	Flushed         int64 `json:"flushed"`
+	Rebased         int64 `json:"rebased"` // penulisan yang disusun ulang karena version conflict
+	FlushErrors     int64 `json:"flushErrors"`
+	DeadLettered    int64 `json:"deadLettered"` // penulisan yang dibuang dari antrian
+}
+
+// DeadLetter adalah penulisan yang dibuang dari antrian karena tidak bisa
+// disimpan. Dokumennya ikut dicatat supaya bisa diperiksa atau ditulis
+// ulang manual.
+type DeadLetter struct {
+	UserID    string          `json:"userId"`
+	Version   int64           `json:"version"` // versi yang sudah dijawab ke pemanggil
+	Data      json.RawMessage `json:"data"`
+	Reason    string          `json:"reason"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"requestId"`
+	Error     string          `json:"error"`
+	QueuedAt  int64           `json:"queuedAt"`
+	FailedAt  int64           `json:"failedAt"`
+}
+
+const (
+	// maxWriteAttempts adalah batas flush gagal karena penulisan yang sama
+	// sebelum penulisan itu dibuang ke dead letter
+	maxWriteAttempts = 3
+	// maxDeadLetters adalah jumlah dead letter terbaru yang disimpan di memori
+	maxDeadLetters = 100
+)
+
+// NewWriteBehindRepository langsung menjalankan flush di background
+func NewWriteBehindRepository(repo *UserRepository, interval time.Duration, maxBatch int) *WriteBehindRepository {
+	w := &WriteBehindRepository{
+		UserRepository: repo,
+		Interval:       interval,
+		MaxBatch:       maxBatch,
+		latest:         make(map[string]cachedUser),
+		kick:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *WriteBehindRepository) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+		if err := w.Flush(context.Background()); err != nil {
+			slog.Error("Gagal flush penulisan user", "err", err)
+		}
+	}
+}
+
+// Close menghentikan flush berkala lalu menulis sisa antrian. Setelah Close,
+// setiap penulisan langsung di-flush. Error kalau antrian belum habis saat
+// ctx selesai.
+func (w *WriteBehindRepository) Close(ctx context.Context) error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stop)
+	<-w.done
+
+	for {
+		err := w.Flush(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d penulisan user belum tersimpan: %w", w.Stats().Pending, err)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// Stats mengembalikan keadaan antrian saat ini
+func (w *WriteBehindRepository) Stats() WriteBehindStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.stats
+	stats.Pending = len(w.queue)
+	if len(w.queue) > 0 {
+		stats.OldestPendingMs = time.Since(w.queue[0].QueuedAt).Milliseconds()
+	}
+	return stats
+}
+
+// DeadLetters mengembalikan penulisan terbaru yang dibuang dari antrian
+func (w *WriteBehindRepository) DeadLetters() []DeadLetter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.deadLetters)
+}
+
+func (w *WriteBehindRepository) lockIndex(userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return int(h.Sum32() % uint32(len(w.locks)))
+}
+
+func (w *WriteBehindRepository) userLock(userID string) *sync.Mutex {
+	return &w.locks[w.lockIndex(userID)]
+}
+
+// current mengembalikan JSON dokumen terbaru user dan versinya: antrian
+// dulu, lalu database langsung (bukan cache yang bisa basi), "" kalau user
+// tidak ada. Pemanggil harus memegang userLock.
+func (w *WriteBehindRepository) current(ctx context.Context, userID string) (string, int64, error) {
+	w.mu.Lock()
+	latest, ok := w.latest[userID]
+	w.mu.Unlock()
+	if ok {
+		return string(latest.Data), latest.Version, nil
+	}
+	dataJSON, version, err := w.loadUser(ctx, w.conn(w.ReadDB), userID, false)
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	return dataJSON, version, err
+}
+
+// enqueue mengantrikan dokumen sebagai versi version+1 di atas base (JSON
+// dokumen sebelumnya). Pemanggil harus memegang userLock.
+func (w *WriteBehindRepository) enqueue(ctx context.Context, userID, base string, user *entity.User, version int64) (int64, error) {
+	dataBytes, err := json.Marshal(user)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	w.queue = append(w.queue, PendingWrite{
+		UserID:          userID,
+		Data:            string(dataBytes),
+		ExpectedVersion: version,
+		Base:            base,
+		Reason:          ReasonFrom(ctx),
+		Actor:           ActorFrom(ctx),
+		RequestID:       RequestIDFrom(ctx),
+		QueuedAt:        time.Now(),
+		ifMatch:         expectsVersion(ctx),
+		check:           rebaseCheckFrom(ctx),
+	})
+	w.latest[userID] = cachedUser{Version: version + 1, Data: dataBytes}
+	full := len(w.queue) >= w.MaxBatch
+	closed := w.closed
+	w.mu.Unlock()
+
+	if closed {
+		// Sudah shutdown, tidak ada flush berkala lagi
+		return version + 1, w.Flush(ctx)
+	}
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return version + 1, nil
+}
+
+// GetUser membaca dokumen dari antrian kalau ada, supaya pembacaan setelah
+// penulisan tidak basi; selain itu lewat cache seperti biasa
+func (w *WriteBehindRepository) GetUser(ctx context.Context, userID string) (*entity.User, int64, error) {
+	w.mu.Lock()
+	latest, ok := w.latest[userID]
+	w.mu.Unlock()
+	if ok {
+		return decodeUserDoc(userID, string(latest.Data)), latest.Version, nil
+	}
+	return w.UserRepository.GetUser(ctx, userID)
+}
+
+// SaveUser mengantrikan dokumen; aturan expectedVersion sama dengan
+// UserRepository.SaveUser
+func (w *WriteBehindRepository) SaveUser(ctx context.Context, userID string, user *entity.User, expectedVersion int64) (int64, error) {
+	lock := w.userLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	base, version, err := w.current(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case expectedVersion > 0 && (base == "" || version != expectedVersion):
+		return 0, ErrVersionConflict
+	case expectedVersion == 0 && base != "":
+		return 0, ErrVersionConflict
+	}
+	return w.enqueue(ctx, userID, base, user, version)
+}
+
+func (w *WriteBehindRepository) MutateUser(ctx context.Context, userID string, fn func(user *entity.User) error) (*entity.User, int64, error) {
+	lock := w.userLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	base, version, err := w.current(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if base == "" {
+		return nil, 0, ErrUserNotFound
+	}
+	if err := checkExpectedVersion(ctx, version); err != nil {
+		return nil, 0, err
+	}
+	user := decodeUserDoc(userID, base)
+	if err := fn(user); err != nil {
+		return nil, 0, err
+	}
+
+	newVersion, err := w.enqueue(ctx, userID, base, user, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	return user, newVersion, nil
+}
+
+// MutateUsers (transfer) tidak ditunda: antrian di-flush dulu, lalu
+// transaksi dijalankan langsung ke database selama user-user terkait dikunci
+func (w *WriteBehindRepository) MutateUsers(ctx context.Context, userIDs []string, fn func(users map[string]*entity.User) error, transfers []Transfer) (map[string]*entity.User, error) {
+	// Kunci urut index supaya dua transfer tidak saling menunggu
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, userID := range userIDs {
+		if i := w.lockIndex(userID); !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		w.locks[i].Lock()
+		defer w.locks[i].Unlock()
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return w.UserRepository.MutateUsers(ctx, userIDs, fn, transfers)
+}
+
+// Flush menulis antrian ke database, maksimal MaxBatch penulisan per
+// transaksi, sampai antrian kosong
+func (w *WriteBehindRepository) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		n := min(len(w.queue), w.MaxBatch)
+		batch := w.queue[:n:n]
+		w.queue = w.queue[n:]
+		w.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		err := w.UserRepository.SaveUsers(ctx, batch)
+		var batchErr *BatchWriteError
+		switch {
+		case err == nil:
+			w.flushed(batch)
+		case errors.As(err, &batchErr) && errors.Is(err, ErrVersionConflict):
+			// Ada penulis lain di luar antrian; penulisan user itu disusun
+			// ulang di atas dokumen database, lalu semuanya dicoba lagi
+			if rebaseErr := w.rebase(ctx, batch, batchErr.UserID); rebaseErr != nil {
+				w.mu.Lock()
+				w.queue = append(batch, w.queue...)
+				w.stats.FlushErrors++
+				w.mu.Unlock()
+				return rebaseErr
+			}
+		case errors.As(err, &batchErr) && ctx.Err() == nil:
+			// Penulisan yang gagal dicoba lagi di flush berikutnya; setelah
+			// maxWriteAttempts kali dibuang supaya sisa antrian tetap tersimpan
+			w.mu.Lock()
+			w.stats.FlushErrors++
+			failed := &batch[batchErr.Index]
+			failed.attempts++
+			if failed.attempts < maxWriteAttempts {
+				w.queue = append(batch, w.queue...)
+				w.mu.Unlock()
+				return err
+			}
+			p := *failed
+			w.queue = append(slices.Delete(batch, batchErr.Index, batchErr.Index+1), w.queue...)
+			w.deadLetter(p, batchErr.Err)
+			if !slices.ContainsFunc(w.queue, func(q PendingWrite) bool { return q.UserID == p.UserID }) {
+				delete(w.latest, p.UserID)
+			}
+			w.mu.Unlock()
+		default:
+			w.mu.Lock()
+			w.queue = append(batch, w.queue...)
+			w.stats.FlushErrors++
+			w.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// deadLetter mencatat p yang dibuang dari antrian. Pemanggil harus memegang
+// w.mu dan sudah mengeluarkan p dari antrian.
+func (w *WriteBehindRepository) deadLetter(p PendingWrite, err error) {
+	w.deadLetters = append(w.deadLetters, DeadLetter{
+		UserID:    p.UserID,
+		Version:   p.ExpectedVersion + 1,
+		Data:      json.RawMessage(p.Data),
+		Reason:    p.Reason,
+		Actor:     p.Actor,
+		RequestID: p.RequestID,
+		Error:     err.Error(),
+		QueuedAt:  p.QueuedAt.UnixMilli(),
+		FailedAt:  time.Now().UnixMilli(),
+	})
+	if len(w.deadLetters) > maxDeadLetters {
+		w.deadLetters = w.deadLetters[len(w.deadLetters)-maxDeadLetters:]
+	}
+	w.stats.DeadLettered++
+	slog.Error("Penulisan user dibuang dari antrian write-behind", "userId", p.UserID, "version", p.ExpectedVersion+1, "err", err)
+}
+
+// flushed mencatat batch yang sudah tersimpan. Dokumen di latest dilepas
+// kalau tidak ada penulisan yang lebih baru di antrian.
+func (w *WriteBehindRepository) flushed(batch []PendingWrite) {
+	now := time.Now()
+	lag := now.Sub(batch[0].QueuedAt).Milliseconds()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, p := range batch {
+		if latest, ok := w.latest[p.UserID]; ok && latest.Version == p.ExpectedVersion+1 {
+			delete(w.latest, p.UserID)
+		}
+	}
+	w.stats.LastFlushAt = now.UnixMilli()
+	w.stats.LastFlushSize = len(batch)
+	w.stats.LastFlushLagMs = lag
+	w.stats.MaxFlushLagMs = max(w.stats.MaxFlushLagMs, lag)
+	w.stats.Flushed += int64(len(batch))
+}
+
+// rebase menyusun ulang semua penulisan userID (di batch maupun antrian) di
+// atas dokumen database terbaru, lalu mengembalikan batch ke depan antrian.
+// Penulisan yang tidak lolos rebaseWrite dibuang ke dead letter; penulisan
+// berikutnya tetap disusun dari perubahannya sendiri saja.
+func (w *WriteBehindRepository) rebase(ctx context.Context, batch []PendingWrite, userID string) error {
+	theirs, version, err := w.loadUser(ctx, w.conn(w.ReadDB), userID, false)
+	if err == sql.ErrNoRows {
+		theirs, version = "", 0
+	} else if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	pending := append(batch, w.queue...)
+	kept := pending[:0]
+	rebased, dropped := 0, 0
+	for _, p := range pending {
+		if p.UserID != userID {
+			kept = append(kept, p)
+			continue
+		}
+		data, err := rebaseWrite(p, theirs)
+		if err != nil {
+			w.deadLetter(p, fmt.Errorf("rebase: %w", err))
+			dropped++
+			continue
+		}
+		p.Base, p.Data, p.ExpectedVersion = theirs, data, version
+		theirs, version = data, version+1
+		kept = append(kept, p)
+		rebased++
+	}
+	w.queue = kept
+	if rebased > 0 {
+		w.latest[userID] = cachedUser{Version: version, Data: json.RawMessage(theirs)}
+	} else {
+		delete(w.latest, userID)
+	}
+	w.stats.Rebased += int64(rebased)
+	w.mu.Unlock()
+
+	w.uncacheWritten(ctx, userID)
+	slog.Warn("Penulisan user di-rebase karena version conflict saat flush", "userId", userID, "count", rebased, "dropped", dropped)
+	return nil
+}
+
+// rebaseWrite menyusun p di atas theirs lalu mengecek ulang syaratnya:
+// penulisan If-Match selalu ditolak (versi yang dicek sudah bukan yang
+// terbaru), syarat WithRebaseCheck dicek terhadap theirs, dan hasilnya
+// harus tetap sesuai schema.
+func rebaseWrite(p PendingWrite, theirs string) (string, error) {
+	if p.ifMatch {
+		return "", ErrVersionConflict
+	}
+	data, err := rebaseDoc(p.Base, p.Data, theirs)
+	if err != nil {
+		return "", err
+	}
+	theirsDoc, dataDoc := decodeUser(theirs), decodeUser(data)
+	if p.check != nil {
+		if err := p.check(theirsDoc); err != nil {
+			return "", err
+		}
+	}
+	if err := validateRebased(theirsDoc, dataDoc); err != nil {
+		return "", err
+	}
+	return data, nil
+}
+
+// validateRebased memeriksa schema dokumen hasil rebase. Pelanggaran pada
+// nilai yang sama dengan dokumen theirs (sudah tersimpan) diabaikan.
+func validateRebased(theirs, data map[string]interface{}) error {
+	for _, e := range entity.ValidateUserMap(data, false) {
+		old, existed := lookupPath(theirs, e.Path)
+		current, _ := lookupPath(data, e.Path)
+		if existed && reflect.DeepEqual(old, current) {
+			continue
+		}
+		return fmt.Errorf("dokumen hasil rebase tidak sesuai schema: %s %s", e.Path, e.Message)
+	}
+	return nil
+}
+
+func lookupPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	var val interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if val, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+// rebaseDoc menerapkan perubahan base -> ours di atas theirs (dokumen yang
+// ditulis pihak lain). Field yang tidak diubah ours memakai nilai theirs,
+// field mata uang (LedgerFields) yang diubah kedua pihak dijumlahkan
+// selisihnya, object digabung per key, selain itu nilai ours yang menang.
+func rebaseDoc(base, ours, theirs string) (string, error) {
+	var baseDoc, oursDoc, theirsDoc map[string]interface{}
+	for _, d := range []struct {
+		raw string
+		doc *map[string]interface{}
+	}{{base, &baseDoc}, {ours, &oursDoc}, {theirs, &theirsDoc}} {
+		if d.raw == "" {
+			*d.doc = map[string]interface{}{}
+			continue
+		}
+		if err := json.Unmarshal([]byte(d.raw), d.doc); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal(rebaseMap(baseDoc, oursDoc, theirsDoc, true))
+	return string(data), err
+}
+
+func rebaseMap(base, ours, theirs map[string]interface{}, topLevel bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(theirs))
+	for k, v := range theirs {
+		result[k] = v
+	}
+	for k := range base {
+		if _, ok := ours[k]; !ok {
+			delete(result, k)
+		}
+	}
+	for k, o := range ours {
+		b, inBase := base[k]
+		if inBase && reflect.DeepEqual(b, o) {
+			continue // tidak diubah ours
+		}
+		t := theirs[k]
+		bn, bNum := b.(float64)
+		on, oNum := o.(float64)
+		tn, tNum := t.(float64)
+		if topLevel && bNum && oNum && tNum && slices.Contains(LedgerFields, k) {
+			result[k] = tn + (on - bn)
+			continue
+		}
+		om, oMap := o.(map[string]interface{})
+		tm, tMap := t.(map[string]interface{})
+		if oMap && tMap {
+			bm, _ := b.(map[string]interface{})
+			result[k] = rebaseMap(bm, om, tm, false)
+			continue
+		}
+		result[k] = o
+	}
+	return result
+}
+
+// flushFor mem-flush antrian hanya kalau userID punya penulisan yang belum
+// tersimpan, supaya pembacaan per user tidak memaksa flush seluruh antrian
+func (w *WriteBehindRepository) flushFor(ctx context.Context, userID string) error {
+	w.mu.Lock()
+	_, pending := w.latest[userID]
+	w.mu.Unlock()
+	if !pending {
+		return nil
+	}
+	return w.Flush(ctx)
+}
+
+// Pembacaan massal di bawah ini langsung ke database tanpa flush (bisa
+// tertinggal paling lama sekitar Interval); GetAFKUsers ditimpa dokumen
+// antrian supaya status afk terbaru tetap terlihat.
+
+func (w *WriteBehindRepository) GetAFKUsers(ctx context.Context) (map[string]*entity.User, error) {
+	users, err := w.UserRepository.GetAFKUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for userID, latest := range w.latest {
+		user := decodeUserDoc(userID, string(latest.Data))
+		if user.Afk > 0 {
+			users[userID] = user
+		} else {
+			delete(users, userID)
+		}
+	}
+	return users, nil
+}
+
+func (w *WriteBehindRepository) GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardEntry, error) {
+	return w.UserRepository.GetLeaderboard(ctx, q)
+}
+
+func (w *WriteBehindRepository) CountUsers(ctx context.Context) (int, error) {
+	return w.UserRepository.CountUsers(ctx)
+}
+
+func (w *WriteBehindRepository) GetUserIDsAfter(ctx context.Context, cursor string, limit int) ([]string, error) {
+	return w.UserRepository.GetUserIDsAfter(ctx, cursor, limit)
+}
+
+// Pembacaan per user di bawah ini flush dulu hanya kalau user itu masih
+// punya penulisan di antrian
+
+func (w *WriteBehindRepository) GetLeaderboardPosition(ctx context.Context, lbType string, asc bool, userID string) (*LeaderboardCursor, int, error) {
+	if err := w.flushFor(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+	return w.UserRepository.GetLeaderboardPosition(ctx, lbType, asc, userID)
+}
+
+func (w *WriteBehindRepository) GetHistory(ctx context.Context, userID, field string, limit int, cursor int64) ([]LedgerEntry, int64, error) {
+	if err := w.flushFor(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+	return w.UserRepository.GetHistory(ctx, userID, field, limit, cursor)
+}
+
+func (w *WriteBehindRepository) GetAuditLog(ctx context.Context, userID string, since int64, limit int) ([]AuditEntry, error) {
+	if err := w.flushFor(ctx, userID); err != nil {
+		return nil, err
+	}
+	return w.UserRepository.GetAuditLog(ctx, userID, since, limit)
+}
+
+func (w *WriteBehindRepository) GetSnapshotAt(ctx context.Context, userID string, at int64) (*UserSnapshot, error) {
+	if err := w.flushFor(ctx, userID); err != nil {
+		return nil, err
+	}
+	return w.UserRepository.GetSnapshotAt(ctx, userID, at)
+}
+
+// CreateBackfillJob flush sekali di awal job supaya semua dokumen ikut
+func (w *WriteBehindRepository) CreateBackfillJob(ctx context.Context, actor string) (*BackfillJob, error) {
+	if err := w.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return w.UserRepository.CreateBackfillJob(ctx, actor)
+}
@@ -0,0 +1,281 @@
+package repository_test
+
+import (
+	"Berpg/internal/cache"
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newWriteBehind: flush hanya manual (interval panjang)
+func newWriteBehind(t *testing.T) (*repository.WriteBehindRepository, *repository.UserRepository) {
+	t.Helper()
+	repo := repository.NewUserRepository(openTestDB(t), cache.NewLRUCache(100), repository.SQLite)
+	w := repository.NewWriteBehindRepository(repo, time.Hour, 500)
+	t.Cleanup(func() { w.Close(context.Background()) })
+	return w, repo
+}
+
+func addMoney(amount float64) func(u *entity.User) error {
+	return func(u *entity.User) error {
+		u.Money += amount
+		return nil
+	}
+}
+
+func TestWriteBehindFlush(t *testing.T) {
+	ctx := context.Background()
+	w, repo := newWriteBehind(t)
+
+	user := entity.NewUser()
+	user.ID = "u1"
+	if _, err := w.SaveUser(ctx, "u1", user, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, version, err := w.MutateUser(ctx, "u1", addMoney(5)); err != nil || version != 2 {
+		t.Fatalf("mutate = versi %d, %v", version, err)
+	}
+
+	// Belum di-flush: database kosong, tapi GetUser membaca antrian
+	if stored, _, _ := repo.GetUser(ctx, "u1"); stored != nil {
+		t.Fatal("sudah tersimpan sebelum flush")
+	}
+	if got, version, _ := w.GetUser(ctx, "u1"); got == nil || got.Money != user.Money+5 || version != 2 {
+		t.Fatalf("GetUser dari antrian = %v versi %d", got, version)
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stored, version, err := repo.GetUser(ctx, "u1")
+	if err != nil || stored == nil || stored.Money != user.Money+5 || version != 2 {
+		t.Fatalf("setelah flush = %v versi %d, %v", stored, version, err)
+	}
+	if st := w.Stats(); st.Pending != 0 || st.Flushed != 2 || st.Rebased != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// Penulisan dari luar antrian (proses lain, admin) tidak boleh membuat
+// penulisan yang sudah dijawab sukses hilang
+func TestWriteBehindRebaseOnConflict(t *testing.T) {
+	ctx := context.Background()
+	w, repo := newWriteBehind(t)
+
+	user := entity.NewUser()
+	user.ID = "u1"
+	user.Money = 100
+	if _, err := repo.SaveUser(ctx, "u1", user, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cache diisi versi 1, lalu pihak lain menulis versi 2 langsung ke
+	// database tanpa lewat cache yang sama
+	if _, _, err := w.GetUser(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	outside := repository.NewUserRepository(&repository.DB{Write: repo.DB, Read: repo.ReadDB}, cache.NoopCache{}, repository.SQLite)
+	if _, _, err := outside.MutateUser(ctx, "u1", func(u *entity.User) error {
+		u.Money += 50
+		u.Kayu = 7
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Base diambil dari database (versi 2), bukan cache yang basi
+	if _, version, err := w.MutateUser(ctx, "u1", addMoney(10)); err != nil || version != 3 {
+		t.Fatalf("mutate = versi %d, %v", version, err)
+	}
+
+	// Pihak lain menulis lagi selagi penulisan masih antri
+	if _, _, err := outside.MutateUser(ctx, "u1", addMoney(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := w.MutateUser(ctx, "u1", addMoney(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stored, version, err := outside.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Money != 100+50+10+1000+1 || stored.Kayu != 7 || version != 5 {
+		t.Errorf("money = %v kayu = %v versi %d", stored.Money, stored.Kayu, version)
+	}
+	if st := w.Stats(); st.Pending != 0 || st.Rebased != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+	if got, _, _ := w.GetUser(ctx, "u1"); got.Money != stored.Money {
+		t.Errorf("GetUser setelah rebase = %v, mau %v", got.Money, stored.Money)
+	}
+}
+
+// Penulisan yang di-rebase dicek ulang: If-Match, syarat WithRebaseCheck,
+// dan schema dokumen hasil rebase. Yang gagal dibuang ke dead letter.
+func TestWriteBehindRebaseRecheck(t *testing.T) {
+	ctx := context.Background()
+	w, repo := newWriteBehind(t)
+	outside := repository.NewUserRepository(&repository.DB{Write: repo.DB, Read: repo.ReadDB}, cache.NoopCache{}, repository.SQLite)
+
+	moneyAtLeast := func(n float64) func(doc map[string]interface{}) error {
+		return func(doc map[string]interface{}) error {
+			if money, _ := doc["money"].(float64); money < n {
+				return errors.New("money kurang")
+			}
+			return nil
+		}
+	}
+	addKayu := func(u *entity.User) error {
+		u.Kayu += 3
+		return nil
+	}
+	tests := []struct {
+		userID    string
+		ctx       context.Context
+		spend     float64
+		wantMoney float64
+		wantDead  bool
+	}{
+		{"tanpa-syarat", ctx, 30, 20, false},
+		{"guard-lolos", repository.WithRebaseCheck(ctx, moneyAtLeast(30)), 30, 20, false},
+		{"guard-gagal", repository.WithRebaseCheck(ctx, moneyAtLeast(80)), 80, 50, true},
+		{"if-match", repository.WithExpectedVersion(ctx, 1), 10, 50, true},
+		{"schema", ctx, 70, 50, true},
+	}
+	for _, tt := range tests {
+		user := entity.NewUser()
+		user.ID, user.Money = tt.userID, 100
+		if _, err := repo.SaveUser(ctx, tt.userID, user, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := w.MutateUser(tt.ctx, tt.userID, addMoney(-tt.spend)); err != nil {
+			t.Fatal(err)
+		}
+		// Penulisan berikutnya tetap disimpan walaupun yang sebelumnya dibuang
+		if _, _, err := w.MutateUser(ctx, tt.userID, addKayu); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := outside.MutateUser(ctx, tt.userID, addMoney(-50)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	dead := make(map[string]bool)
+	for _, d := range w.DeadLetters() {
+		dead[d.UserID] = true
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			stored, _, err := outside.GetUser(ctx, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Money != tt.wantMoney || stored.Kayu != 3 {
+				t.Errorf("money = %v kayu = %v, mau %v dan 3", stored.Money, stored.Kayu, tt.wantMoney)
+			}
+			if dead[tt.userID] != tt.wantDead {
+				t.Errorf("dead letter = %v, mau %v", dead[tt.userID], tt.wantDead)
+			}
+		})
+	}
+	if st := w.Stats(); st.Pending != 0 || st.DeadLettered != 3 || st.Rebased != 7 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestWriteBehindAFKOverlay(t *testing.T) {
+	ctx := context.Background()
+	w, repo := newWriteBehind(t)
+
+	for id, afk := range map[string]float64{"afk": 1, "balik": 1, "baru": -1} {
+		user := entity.NewUser()
+		user.ID, user.Afk = id, afk
+		if _, err := repo.SaveUser(ctx, id, user, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setAFK := func(afk float64) func(u *entity.User) error {
+		return func(u *entity.User) error {
+			u.Afk = afk
+			return nil
+		}
+	}
+	w.MutateUser(ctx, "balik", setAFK(-1))
+	w.MutateUser(ctx, "baru", setAFK(5))
+
+	users, err := w.GetAFKUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]bool{"afk": true, "balik": false, "baru": true} {
+		if _, ok := users[id]; ok != want {
+			t.Errorf("%s afk = %v, mau %v", id, ok, want)
+		}
+	}
+	if st := w.Stats(); st.Pending != 2 {
+		t.Errorf("GetAFKUsers mem-flush antrian: %+v", st)
+	}
+}
+
+// Penulisan yang terus gagal (bukan version conflict) dibuang ke dead
+// letter setelah beberapa kali flush, penulisan lain tetap tersimpan
+func TestWriteBehindDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	w, repo := newWriteBehind(t)
+
+	for _, id := range []string{"rusak", "u2"} {
+		user := entity.NewUser()
+		user.ID, user.Money = id, 100
+		if _, err := repo.SaveUser(ctx, id, user, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := repo.DB.Exec(`
+	CREATE TRIGGER tolak_rusak BEFORE UPDATE ON users WHEN NEW.id = 'rusak'
+	BEGIN SELECT RAISE(ABORT, 'rusak'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.MutateUser(ctx, "rusak", addMoney(10))
+	w.MutateUser(ctx, "u2", addMoney(10))
+
+	for i := 1; i < 3; i++ {
+		if err := w.Flush(ctx); err == nil {
+			t.Fatalf("flush ke-%d harusnya gagal", i)
+		}
+	}
+	if st := w.Stats(); st.Pending != 2 || st.DeadLettered != 0 {
+		t.Fatalf("penulisan dibuang sebelum batas percobaan: %+v", st)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := w.Stats(); st.Pending != 0 || st.DeadLettered != 1 || st.FlushErrors != 3 {
+		t.Errorf("stats = %+v", st)
+	}
+	dead := w.DeadLetters()
+	if len(dead) != 1 || dead[0].UserID != "rusak" || dead[0].Version != 2 || dead[0].Error == "" {
+		t.Fatalf("dead letters = %+v", dead)
+	}
+	if stored, _, _ := repo.GetUser(ctx, "u2"); stored.Money != 110 {
+		t.Errorf("u2 money = %v, mau 110", stored.Money)
+	}
+	if got, version, _ := w.GetUser(ctx, "rusak"); got.Money != 100 || version != 1 {
+		t.Errorf("rusak setelah dibuang = %v versi %d, mau dokumen database", got.Money, version)
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRebaseDoc(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+	}{
+		{"mata uang dijumlah selisihnya",
+			`{"money":100}`, `{"money":150}`, `{"money":80}`,
+			`{"money":130}`},
+		{"field lain ours menang",
+			`{"lastDaily":1}`, `{"lastDaily":5}`, `{"lastDaily":3}`,
+			`{"lastDaily":5}`},
+		{"field yang tidak diubah ours pakai theirs",
+			`{"money":1,"kayu":0}`, `{"money":2,"kayu":0}`, `{"money":1,"kayu":7}`,
+			`{"money":2,"kayu":7}`},
+		{"key yang dihapus ours tetap dihapus",
+			`{"a":1,"b":2}`, `{"a":1}`, `{"a":1,"b":3}`,
+			`{"a":1}`},
+		{"key baru dari theirs tetap ada",
+			`{"a":1}`, `{"a":2}`, `{"a":1,"c":9}`,
+			`{"a":2,"c":9}`},
+		{"object digabung per key",
+			`{"rpg":{"level":1,"exp":0}}`, `{"rpg":{"level":2,"exp":0}}`, `{"rpg":{"level":1,"exp":50}}`,
+			`{"rpg":{"level":2,"exp":50}}`},
+		{"mata uang nested tidak dijumlah",
+			`{"rpg":{"money":1}}`, `{"rpg":{"money":3}}`, `{"rpg":{"money":5}}`,
+			`{"rpg":{"money":3}}`},
+		{"mata uang salah tipe ours menang",
+			`{"money":"x"}`, `{"money":10}`, `{"money":"y"}`,
+			`{"money":10}`},
+		{"user baru yang ditulis pihak lain",
+			``, `{"money":100,"name":"a"}`, `{"money":100,"name":"b"}`,
+			`{"money":100,"name":"a"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rebaseDoc(tt.base, tt.ours, tt.theirs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotDoc, wantDoc map[string]interface{}
+			json.Unmarshal([]byte(got), &gotDoc)
+			json.Unmarshal([]byte(tt.want), &wantDoc)
+			if !reflect.DeepEqual(gotDoc, wantDoc) {
+				t.Errorf("rebaseDoc = %s, mau %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import "context"
+
+// Keterangan penulisan (alasan, request ID, identitas API key) dibawa lewat
+// context supaya bisa ikut tercatat di ledger dan audit log tanpa mengubah
+// signature SaveUser/MutateUser.
+
+type reasonKey struct{}
+type requestIDKey struct{}
+type actorKey struct{}
+type expectedVersionKey struct{}
+type rebaseCheckKey struct{}
+
+// WithReason memberi alasan penulisan, misal "daily", "transfer", "ops"
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey{}, reason)
+}
+
+// WithRequestID menyimpan ID request HTTP yang memicu penulisan
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// WithActor menyimpan identitas pemanggil (API key) yang melakukan penulisan
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ReasonFrom mengembalikan alasan penulisan ("" kalau tidak ada)
+func ReasonFrom(ctx context.Context) string {
+	reason, _ := ctx.Value(reasonKey{}).(string)
+	return reason
+}
+
+// RequestIDFrom mengembalikan ID request ("" kalau tidak ada)
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ActorFrom mengembalikan identitas pemanggil ("" kalau tidak ada)
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithExpectedVersion membuat MutateUser gagal dengan ErrVersionConflict
+// kalau versi dokumen yang dibaca bukan version (header If-Match).
+// AnyVersion = tanpa syarat.
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// checkExpectedVersion dipanggil MutateUser setelah dokumen dibaca
+func checkExpectedVersion(ctx context.Context, version int64) error {
+	expected, ok := ctx.Value(expectedVersionKey{}).(int64)
+	if ok && expected != AnyVersion && expected != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// expectsVersion melaporkan apakah penulisan bersyarat versi (If-Match)
+func expectsVersion(ctx context.Context) bool {
+	expected, ok := ctx.Value(expectedVersionKey{}).(int64)
+	return ok && expected != AnyVersion
+}
+
+// WithRebaseCheck memberi syarat penulisan (guard, cooldown, saldo, dll)
+// yang dicek ulang kalau write-behind harus menyusun ulang penulisan ini di
+// atas dokumen yang ditulis pihak lain. check menerima dokumen pihak lain
+// (sebelum penulisan ini); error membuat penulisan dibuang.
+func WithRebaseCheck(ctx context.Context, check func(doc map[string]interface{}) error) context.Context {
+	return context.WithValue(ctx, rebaseCheckKey{}, check)
+}
+
+func rebaseCheckFrom(ctx context.Context) func(doc map[string]interface{}) error {
+	check, _ := ctx.Value(rebaseCheckKey{}).(func(doc map[string]interface{}) error)
+	return check
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// jumlah user yang diproses sebelum progress disimpan
+const backfillChunkSize = 200
+
+// ErrBackfillRunning dikembalikan kalau masih ada job backfill yang berjalan
+var ErrBackfillRunning = errors.New("backfill masih berjalan")
+
+// StartBackfill mengisi field default yang belum ada ke semua user (logic
+// sinkronisasi yang sama dengan GetOrInitUser) di background. Job terakhir
+// yang belum selesai (server mati / gagal) dilanjutkan dari cursor-nya;
+// kalau sudah selesai, job baru dibuat dari awal.
+func (s *UserService) StartBackfill(ctx context.Context) (*repository.BackfillJob, error) {
+	s.backfillMu.Lock()
+	defer s.backfillMu.Unlock()
+	if s.backfillRunning {
+		return nil, ErrBackfillRunning
+	}
+
+	job, err := s.Repo.GetLatestBackfillJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actor := repository.ActorFrom(ctx)
+	if job == nil || job.Status == repository.BackfillDone {
+		job, err = s.Repo.CreateBackfillJob(ctx, actor)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		job.Status = repository.BackfillRunning
+		job.Error = ""
+		if err := s.Repo.SaveBackfillJob(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+
+	// Context request selesai begitu response terkirim, jadi job pakai
+	// context sendiri (actor tetap dibawa untuk audit log)
+	jobCtx := repository.WithReason(context.Background(), "backfill")
+	jobCtx = repository.WithActor(jobCtx, actor)
+	s.backfillRunning = true
+	progress := *job
+	go s.runBackfill(jobCtx, job)
+	return &progress, nil
+}
+
+// GetBackfillProgress mengembalikan job backfill terakhir (nil kalau belum ada)
+func (s *UserService) GetBackfillProgress(ctx context.Context) (*repository.BackfillJob, error) {
+	return s.Repo.GetLatestBackfillJob(ctx)
+}
+
+func (s *UserService) runBackfill(ctx context.Context, job *repository.BackfillJob) {
+	defer func() {
+		s.backfillMu.Lock()
+		s.backfillRunning = false
+		s.backfillMu.Unlock()
+	}()
+
+	slog.Info("Backfill dimulai", "job", job.ID, "cursor", job.Cursor, "total", job.Total)
+	for {
+		ids, err := s.Repo.GetUserIDsAfter(ctx, job.Cursor, backfillChunkSize)
+		if err != nil {
+			s.failBackfill(ctx, job, err)
+			return
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			var saved bool
+			err := retryOnConflict(func() error {
+				var err error
+				_, _, saved, err = s.getOrInitUser(ctx, id, "")
+				return err
+			})
+			if err != nil {
+				s.failBackfill(ctx, job, err)
+				return
+			}
+			if saved {
+				job.Updated++
+			}
+			job.Processed++
+			job.Cursor = id
+		}
+
+		// User baru bisa bertambah selama job berjalan
+		if job.Processed > job.Total {
+			job.Total = job.Processed
+		}
+		if err := s.Repo.SaveBackfillJob(ctx, job); err != nil {
+			slog.Error("Gagal simpan progress backfill", "job", job.ID, "err", err)
+		}
+		slog.Info("Progress backfill", "job", job.ID, "processed", job.Processed, "total", job.Total, "updated", job.Updated)
+	}
+
+	job.Status = repository.BackfillDone
+	job.FinishedAt = time.Now().UnixMilli()
+	if err := s.Repo.SaveBackfillJob(ctx, job); err != nil {
+		slog.Error("Gagal simpan progress backfill", "job", job.ID, "err", err)
+	}
+	slog.Info("Backfill selesai", "job", job.ID, "processed", job.Processed, "updated", job.Updated)
+}
+
+func (s *UserService) failBackfill(ctx context.Context, job *repository.BackfillJob, err error) {
+	slog.Error("Backfill gagal", "job", job.ID, "cursor", job.Cursor, "err", err)
+	job.Status = repository.BackfillFailed
+	job.Error = err.Error()
+	if err := s.Repo.SaveBackfillJob(ctx, job); err != nil {
+		slog.Error("Gagal simpan progress backfill", "job", job.ID, "err", err)
+	}
+}
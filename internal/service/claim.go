@@ -0,0 +1,342 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClaimPeriod adalah satu jenis klaim hadiah berkala (daily, weekly, ...).
+// Field menyimpan timestamp (ms) klaim terakhir; Rewards adalah jumlah yang
+// ditambahkan ke setiap path field angka user (uang, exp, item, dll).
+type ClaimPeriod struct {
+	Field    string             `json:"field"`
+	Cooldown Duration           `json:"cooldown"`
+	Label    string             `json:"label"`
+	Rewards  map[string]float64 `json:"rewards"`
+	// StreakTiers (hanya periode daily) menaikkan hadiah sesuai dailyStreak
+	StreakTiers []StreakTier `json:"streakTiers,omitempty"`
+	// Reset: ResetRolling (default, Cooldown sejak klaim terakhir) atau
+	// ResetFixed (reset di jam ResetAt "HH:MM" waktu lokal APP_TIMEZONE)
+	Reset   string `json:"reset,omitempty"`
+	ResetAt string `json:"resetAt,omitempty"`
+}
+
+// Mode reset klaim
+const (
+	ResetRolling = "rolling"
+	ResetFixed   = "fixed"
+)
+
+const day = 24 * time.Hour
+
+// resetClock mengembalikan jam dan menit ResetAt (default 00:00)
+func (c ClaimPeriod) resetClock() (hour, minute int, err error) {
+	if c.ResetAt == "" {
+		return 0, 0, nil
+	}
+	t, err := time.Parse("15:04", c.ResetAt)
+	if err != nil {
+		return 0, 0, fmt.Errorf("resetAt '%s' harus format HH:MM", c.ResetAt)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// validateReset: mode fixed butuh cooldown kelipatan hari, atau kurang dari
+// sehari dan membagi habis 24 jam (misal 1h, 6h) supaya jadwalnya tetap
+func (c ClaimPeriod) validateReset(period string) error {
+	switch c.Reset {
+	case "", ResetRolling:
+		return nil
+	case ResetFixed:
+	default:
+		return fmt.Errorf("periode '%s': reset harus '%s' atau '%s'", period, ResetRolling, ResetFixed)
+	}
+	if _, _, err := c.resetClock(); err != nil {
+		return fmt.Errorf("periode '%s': %w", period, err)
+	}
+	cd := time.Duration(c.Cooldown)
+	if cd%day != 0 && (cd > day || day%cd != 0 || cd%time.Minute != 0) {
+		return fmt.Errorf("periode '%s': reset fixed butuh cooldown kelipatan 24h atau pembagi 24h", period)
+	}
+	return nil
+}
+
+// nextReset adalah waktu reset (ms) pertama setelah klaim di lastMs untuk
+// mode fixed. Hari reset dimulai jam ResetAt waktu lokal; cooldown kelipatan
+// hari reset di hari ke-N berikutnya, cooldown di bawah sehari reset di slot
+// berikutnya (misal hourly: setiap pergantian jam).
+func (c ClaimPeriod) nextReset(lastMs int64) int64 {
+	hour, minute, _ := c.resetClock()
+	last := time.UnixMilli(lastMs)
+	offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	y, m, d := last.Add(-offset).Date()
+
+	cd := time.Duration(c.Cooldown)
+	if cd < day {
+		dayStart := time.Date(y, m, d, hour, minute, 0, 0, time.Local)
+		slot := last.Sub(dayStart) / cd
+		return dayStart.Add((slot + 1) * cd).UnixMilli()
+	}
+	return time.Date(y, m, d+int(cd/day), hour, minute, 0, 0, time.Local).UnixMilli()
+}
+
+// dayOf adalah nomor hari reset timestamp ms: hari kalender lokal, digeser
+// ResetAt untuk mode fixed (klaim jam 02:00 dengan resetAt 05:00 masih
+// dihitung hari sebelumnya)
+func (c ClaimPeriod) dayOf(ms int64) int64 {
+	if c.Reset == ResetFixed {
+		hour, minute, _ := c.resetClock()
+		ms -= (int64(hour)*60 + int64(minute)) * time.Minute.Milliseconds()
+	}
+	return dayNumber(ms)
+}
+
+// Duration adalah time.Duration yang ditulis sebagai string di file config
+// (format time.ParseDuration, misal "24h", "168h")
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("durasi harus string, misal \"24h\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DefaultClaims dipakai kalau file config klaim tidak ada (lihat
+// claims.example.json). Hadiah daily sama dengan ClaimDaily versi lama.
+var DefaultClaims = map[string]ClaimPeriod{
+	"hourly": {Field: "lasthourly", Cooldown: Duration(time.Hour), Label: "per jam",
+		Rewards: map[string]float64{"money": 1000, "rpg.exp": 50}},
+	"daily": {Field: "lastDaily", Cooldown: Duration(24 * time.Hour), Label: "harian",
+		Rewards: map[string]float64{"money": 10000, "rpg.exp": 200, "diamond": 1}, StreakTiers: defaultStreakTiers},
+	"weekly": {Field: "lastWeekly", Cooldown: Duration(7 * 24 * time.Hour), Label: "mingguan",
+		Rewards: map[string]float64{"money": 100000, "rpg.exp": 2000, "diamond": 10, "common": 5, "potion": 5}},
+	"monthly": {Field: "lastmonthly", Cooldown: Duration(30 * 24 * time.Hour), Label: "bulanan",
+		Rewards: map[string]float64{"money": 500000, "rpg.exp": 10000, "diamond": 50, "uncommon": 5, "mythic": 1}},
+	"yearly": {Field: "lastyearly", Cooldown: Duration(365 * 24 * time.Hour), Label: "tahunan",
+		Rewards: map[string]float64{"money": 10000000, "rpg.exp": 100000, "diamond": 500, "legendary": 5}},
+}
+
+var claimPeriodName = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// LoadClaims membaca tabel klaim dari file JSON {"<period>": ClaimPeriod}.
+// os.ErrNotExist dikembalikan apa adanya kalau file tidak ada.
+func LoadClaims(path string) (map[string]ClaimPeriod, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]ClaimPeriod
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return claims, nil
+}
+
+// ValidateClaims mengecek tabel klaim terhadap schema user dan
+// CooldownRegistry. Label kosong diisi nama period.
+func ValidateClaims(claims map[string]ClaimPeriod) error {
+	if len(claims) == 0 {
+		return errors.New("tidak ada periode klaim")
+	}
+	fields := make(map[string]string)
+	for period, claim := range claims {
+		switch {
+		case !claimPeriodName.MatchString(period):
+			return fmt.Errorf("periode '%s': nama hanya boleh huruf kecil dan angka", period)
+		case !entity.IsNumericPath(claim.Field):
+			return fmt.Errorf("periode '%s': field '%s' bukan field angka user", period, claim.Field)
+		case fields[claim.Field] != "":
+			return fmt.Errorf("periode '%s': field '%s' sudah dipakai periode '%s'", period, claim.Field, fields[claim.Field])
+		case claim.Cooldown <= 0:
+			return fmt.Errorf("periode '%s': cooldown wajib diisi", period)
+		case len(claim.Rewards) == 0:
+			return fmt.Errorf("periode '%s': rewards kosong", period)
+		}
+		if _, ok := CooldownFor(period); ok {
+			return fmt.Errorf("periode '%s': bentrok dengan aksi di CooldownRegistry", period)
+		}
+		for path, amount := range claim.Rewards {
+			if !entity.IsNumericPath(path) {
+				return fmt.Errorf("periode '%s': reward '%s' bukan field angka user", period, path)
+			}
+			if amount <= 0 {
+				return fmt.Errorf("periode '%s': reward '%s' harus lebih dari 0", period, path)
+			}
+		}
+		if err := claim.validateReset(period); err != nil {
+			return err
+		}
+		if err := validateStreakTiers(period, claim.StreakTiers); err != nil {
+			return err
+		}
+		if claim.Label == "" {
+			claim.Label = period
+			claims[period] = claim
+		}
+		fields[claim.Field] = period
+	}
+	return nil
+}
+
+// cooldown mengembalikan aturan cooldown klaim untuk period
+func (c ClaimPeriod) cooldown(period string) Cooldown {
+	cd := Cooldown{Action: period, Field: c.Field, Duration: time.Duration(c.Cooldown), Claim: true}
+	if c.Reset == ResetFixed {
+		cd.NextReady = c.nextReset
+	}
+	return cd
+}
+
+// ErrUnknownClaim dikembalikan kalau periode klaim tidak ada di tabel klaim
+var ErrUnknownClaim = errors.New("periode klaim tidak dikenal")
+
+// ClaimResult adalah hasil klaim yang berhasil. Rewards adalah hadiah yang
+// benar-benar diberikan (setelah bonus streak).
+type ClaimResult struct {
+	Period      string
+	Claim       ClaimPeriod
+	Rewards     map[string]float64
+	Streak      *StreakResult // nil untuk periode selain StreakPeriod
+	User        *entity.User
+	ClaimedAt   int64
+	NextClaimAt int64
+}
+
+// Message: "Berhasil klaim harian! Dapat Rp 10000, 1 Diamond dan 200 Exp."
+func (r *ClaimResult) Message() string {
+	msg := fmt.Sprintf("Berhasil klaim %s! Dapat %s.", r.Claim.Label, formatRewards(r.Rewards))
+	if r.Streak != nil {
+		msg += " " + r.Streak.message()
+	}
+	return msg
+}
+
+// Values mengembalikan nilai terbaru setiap field reward
+func (r *ClaimResult) Values() map[string]interface{} {
+	doc := r.User.ToMap()
+	values := make(map[string]interface{}, len(r.Rewards))
+	for path := range r.Rewards {
+		values[path], _ = getPath(doc, path)
+	}
+	return values
+}
+
+// formatRewards: money ditulis "Rp ...", lalu diamond, lalu field lain urut abjad
+func formatRewards(rewards map[string]float64) string {
+	paths := make([]string, 0, len(rewards))
+	for path := range rewards {
+		paths = append(paths, path)
+	}
+	order := func(path string) string {
+		switch path {
+		case "money":
+			return "0"
+		case "diamond":
+			return "1"
+		}
+		return "2" + path
+	}
+	sort.Slice(paths, func(i, j int) bool { return order(paths[i]) < order(paths[j]) })
+
+	parts := make([]string, len(paths))
+	for i, path := range paths {
+		switch path {
+		case "money":
+			parts[i] = fmt.Sprintf("Rp %.0f", rewards[path])
+		case "diamond":
+			parts[i] = fmt.Sprintf("%.0f Diamond", rewards[path])
+		case "rpg.exp":
+			parts[i] = fmt.Sprintf("%.0f Exp", rewards[path])
+		default:
+			parts[i] = fmt.Sprintf("%.0f %s", rewards[path], path)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " dan " + parts[len(parts)-1]
+}
+
+// ClaimPeriods mengembalikan nama semua periode klaim, urut abjad
+func (s *UserService) ClaimPeriods() []string {
+	periods := make([]string, 0, len(s.Claims))
+	for period := range s.Claims {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+	return periods
+}
+
+// Claim mengecek cooldown periode klaim lalu menambahkan hadiahnya secara
+// atomik (cooldown dan hadiah tersimpan bersama dalam satu MutateUser).
+// Cooldown yang belum selesai dikembalikan sebagai *CooldownError.
+func (s *UserService) Claim(ctx context.Context, userID, period string) (*ClaimResult, error) {
+	claim, ok := s.Claims[period]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownClaim, period)
+	}
+
+	now := time.Now().UnixMilli()
+	ctx = withDefaultReason(ctx, period)
+	ctx = withCooldownRecheck(ctx, claim.cooldown(period), now)
+	result := &ClaimResult{Period: period, Claim: claim}
+	user, _, err := s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		premium := isPremium(user, now)
+		return s.mutateAsMap(user, func(doc map[string]interface{}) error {
+			// streak dihitung dari field klaim sebelum dicap ulang (kalau
+			// cooldown belum selesai, perubahan doc dibuang)
+			var streak *StreakResult
+			if period == StreakPeriod {
+				var err error
+				if streak, err = advanceStreak(doc, claim, now); err != nil {
+					return err
+				}
+			}
+			st, err := stampCooldown(doc, claim.cooldown(period), premium, now)
+			if err != nil {
+				return err
+			}
+			rewards := claim.Rewards
+			if streak != nil {
+				rewards = streakRewards(rewards, streak)
+			}
+			for path, amount := range rewards {
+				current, _ := getPath(doc, path)
+				currentNum, _ := current.(float64)
+				if err := setPath(doc, path, currentNum+amount); err != nil {
+					return err
+				}
+			}
+			result.Rewards, result.Streak = rewards, streak
+			result.ClaimedAt, result.NextClaimAt = now, st.ReadyAt
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.User = user
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextReset(t *testing.T) {
+	withLocal(t, wib)
+	tests := []struct {
+		name  string
+		claim ClaimPeriod
+		last  int64
+		want  int64
+	}{
+		{"daily 00:00", ClaimPeriod{Cooldown: Duration(day), Reset: ResetFixed},
+			msAt(2026, 3, 1, 23, 59), msAt(2026, 3, 2, 0, 0)},
+		{"daily resetAt 05:00 sebelum jam reset", ClaimPeriod{Cooldown: Duration(day), Reset: ResetFixed, ResetAt: "05:00"},
+			msAt(2026, 3, 2, 2, 0), msAt(2026, 3, 2, 5, 0)},
+		{"daily resetAt 05:00 setelah jam reset", ClaimPeriod{Cooldown: Duration(day), Reset: ResetFixed, ResetAt: "05:00"},
+			msAt(2026, 3, 2, 6, 0), msAt(2026, 3, 3, 5, 0)},
+		{"weekly", ClaimPeriod{Cooldown: Duration(7 * day), Reset: ResetFixed},
+			msAt(2026, 3, 1, 12, 0), msAt(2026, 3, 8, 0, 0)},
+		{"hourly", ClaimPeriod{Cooldown: Duration(time.Hour), Reset: ResetFixed},
+			msAt(2026, 3, 1, 12, 30), msAt(2026, 3, 1, 13, 0)},
+		{"6 jam dengan resetAt 01:00", ClaimPeriod{Cooldown: Duration(6 * time.Hour), Reset: ResetFixed, ResetAt: "01:00"},
+			msAt(2026, 3, 1, 12, 30), msAt(2026, 3, 1, 13, 0)},
+		{"hourly lewat tengah malam", ClaimPeriod{Cooldown: Duration(time.Hour), Reset: ResetFixed},
+			msAt(2026, 3, 1, 23, 30), msAt(2026, 3, 2, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claim.nextReset(tt.last); got != tt.want {
+				t.Errorf("nextReset = %v, mau %v", time.UnixMilli(got), time.UnixMilli(tt.want))
+			}
+		})
+	}
+}
+
+func TestDayOf(t *testing.T) {
+	withLocal(t, wib)
+	rolling := ClaimPeriod{}
+	fixed5 := ClaimPeriod{Reset: ResetFixed, ResetAt: "05:00"}
+	tests := []struct {
+		name  string
+		claim ClaimPeriod
+		a, b  int64
+		diff  int64
+	}{
+		{"23:59 dan 00:01", rolling, msAt(2026, 3, 1, 23, 59), msAt(2026, 3, 2, 0, 1), 1},
+		{"hari yang sama", rolling, msAt(2026, 3, 1, 0, 0), msAt(2026, 3, 1, 23, 59), 0},
+		{"resetAt 05:00 masih hari sebelumnya", fixed5, msAt(2026, 3, 1, 23, 0), msAt(2026, 3, 2, 4, 59), 0},
+		{"resetAt 05:00 lewat jam reset", fixed5, msAt(2026, 3, 1, 23, 0), msAt(2026, 3, 2, 5, 0), 1},
+		{"akhir tahun", rolling, msAt(2026, 12, 31, 12, 0), msAt(2027, 1, 1, 12, 0), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claim.dayOf(tt.b) - tt.claim.dayOf(tt.a); got != tt.diff {
+				t.Errorf("selisih hari = %d, mau %d", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	base := func(edit func(c *ClaimPeriod)) map[string]ClaimPeriod {
+		c := ClaimPeriod{Field: "lasthourly", Cooldown: Duration(time.Hour), Rewards: map[string]float64{"money": 1}}
+		edit(&c)
+		return map[string]ClaimPeriod{"hourly": c}
+	}
+	tests := []struct {
+		name   string
+		claims map[string]ClaimPeriod
+		ok     bool
+	}{
+		{"default", DefaultClaims, true},
+		{"kosong", map[string]ClaimPeriod{}, false},
+		{"valid", base(func(c *ClaimPeriod) {}), true},
+		{"nama periode", map[string]ClaimPeriod{"Hourly": base(func(c *ClaimPeriod) {})["hourly"]}, false},
+		{"field bukan angka", base(func(c *ClaimPeriod) { c.Field = "username" }), false},
+		{"cooldown kosong", base(func(c *ClaimPeriod) { c.Cooldown = 0 }), false},
+		{"reward bukan angka", base(func(c *ClaimPeriod) { c.Rewards = map[string]float64{"job": 1} }), false},
+		{"reward negatif", base(func(c *ClaimPeriod) { c.Rewards = map[string]float64{"money": -1} }), false},
+		{"reset tidak dikenal", base(func(c *ClaimPeriod) { c.Reset = "weekly" }), false},
+		{"resetAt salah format", base(func(c *ClaimPeriod) { c.Reset, c.ResetAt = ResetFixed, "25:00" }), false},
+		{"fixed 7 jam", base(func(c *ClaimPeriod) { c.Reset, c.Cooldown = ResetFixed, Duration(7*time.Hour) }), false},
+		{"fixed resetAt 05:00", base(func(c *ClaimPeriod) { c.Reset, c.ResetAt = ResetFixed, "05:00" }), true},
+		{"streakTiers selain daily", base(func(c *ClaimPeriod) { c.StreakTiers = defaultStreakTiers }), false},
+		{"bentrok CooldownRegistry", map[string]ClaimPeriod{"adventure": base(func(c *ClaimPeriod) {})["hourly"]}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateClaims(tt.claims); (err == nil) != tt.ok {
+				t.Errorf("ValidateClaims = %v, mau lolos %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, "u1", nil)
+
+	res, err := s.Claim(ctx, "u1", "weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.User.Money != 100000+100000 || res.User.Diamond != 10 || res.User.Potion != 5 {
+		t.Errorf("hadiah weekly: money=%v diamond=%v potion=%v", res.User.Money, res.User.Diamond, res.User.Potion)
+	}
+	if want := res.ClaimedAt + (7 * day).Milliseconds(); res.NextClaimAt != want {
+		t.Errorf("nextClaimAt = %d, mau %d", res.NextClaimAt, want)
+	}
+
+	_, err = s.Claim(ctx, "u1", "weekly")
+	var cooldownErr *CooldownError
+	if !errors.As(err, &cooldownErr) || cooldownErr.ReadyAt != res.NextClaimAt {
+		t.Fatalf("klaim kedua = %v, mau *CooldownError", err)
+	}
+	user, _, _ := s.Repo.GetUser(ctx, "u1")
+	if user.Money != res.User.Money {
+		t.Errorf("klaim yang ditolak mengubah money: %v", user.Money)
+	}
+
+	if _, err := s.Claim(ctx, "u1", "tidakada"); !errors.Is(err, ErrUnknownClaim) {
+		t.Errorf("periode tidak dikenal = %v", err)
+	}
+	if _, err := s.Claim(ctx, "nobody", "weekly"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user tidak ada = %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Cooldown adalah aturan jeda satu aksi. Field menyimpan timestamp
+// (milidetik) terakhir aksi dijalankan.
+type Cooldown struct {
+	Action   string
+	Field    string
+	Duration time.Duration
+	// PremiumMultiplier mengalikan Duration untuk user premium (premiumTime
+	// belum lewat), misal 0.5 = separuh. 0 berarti sama dengan user biasa.
+	PremiumMultiplier float64
+	// Claim: cooldown klaim hadiah (dari tabel klaim), hanya dicap lewat
+	// endpoint klaim
+	Claim bool
+	// NextReady, kalau diisi, menggantikan lastMs + Duration sebagai waktu
+	// siap (ms), misal klaim dengan reset fixed
+	NextReady func(lastMs int64) int64
+}
+
+// CooldownRegistry adalah daftar cooldown aksi user. Cooldown klaim hadiah
+// (daily, weekly, ...) ada di tabel klaim, lihat ClaimPeriod.
+var CooldownRegistry = []Cooldown{
+	// Aktivitas RPG
+	{Action: "adventure", Field: "lastadventure", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "dungeon", Field: "lastdungeon", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "duel", Field: "lastduel", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "war", Field: "lastwar", Duration: 30 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "kill", Field: "lastkill", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "bunuhi", Field: "lastbunuhi", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "hunt", Field: "lasthunt", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "berburu", Field: "lastberburu", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "mancing", Field: "lastmancing", Duration: 3 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "fishing", Field: "lastfishing", Duration: 3 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "nambang", Field: "lastnambang", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "tambang", Field: "lasttambang", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "mining", Field: "lastmining", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "nebang", Field: "lastnebang", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "mulung", Field: "lastmulung", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "berkebon", Field: "lastberkebon", Duration: 10 * time.Minute, PremiumMultiplier: 0.5},
+
+	// Kerja dan usaha
+	{Action: "kerja", Field: "lastkerja", Duration: time.Hour, PremiumMultiplier: 0.5},
+	{Action: "jobkerja", Field: "lastjobkerja", Duration: time.Hour, PremiumMultiplier: 0.5},
+	{Action: "jobchange", Field: "lastjobchange", Duration: 24 * time.Hour},
+	{Action: "ngojek", Field: "lastngojek", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "grab", Field: "lastgrab", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "taxi", Field: "lasttaxi", Duration: 5 * time.Minute, PremiumMultiplier: 0.5},
+	{Action: "dagang", Field: "lastdagang", Duration: time.Hour, PremiumMultiplier: 0.5},
+	{Action: "bisnis", Field: "lastbisnis", Duration: time.Hour, PremiumMultiplier: 0.5},
+	{Action: "berbisnis", Field: "lastberbisnis", Duration: time.Hour, PremiumMultiplier: 0.5},
+	{Action: "youtuber", Field: "lastyoutuber", Duration: time.Hour, PremiumMultiplier: 0.5},
+
+	// Kriminal
+	{Action: "maling", Field: "lastmaling", Duration: time.Hour},
+	{Action: "rob", Field: "lastrob", Duration: time.Hour},
+	{Action: "rampok", Field: "lastrampok", Duration: time.Hour},
+
+	// Lain-lain
+	{Action: "bansos", Field: "lastbansos", Duration: 24 * time.Hour},
+	{Action: "gift", Field: "lastgift", Duration: 24 * time.Hour},
+	{Action: "slot", Field: "lastslot", Duration: 30 * time.Second},
+}
+
+// CooldownFor mencari cooldown aksi di CooldownRegistry
+func CooldownFor(action string) (Cooldown, bool) {
+	for _, c := range CooldownRegistry {
+		if c.Action == action {
+			return c, true
+		}
+	}
+	return Cooldown{}, false
+}
+
+// ErrUnknownCooldown dikembalikan kalau action tidak ada di CooldownRegistry
+var ErrUnknownCooldown = errors.New("aksi cooldown tidak dikenal")
+
+// CooldownError dikembalikan kalau cooldown aksi belum selesai
+type CooldownError struct {
+	Action    string
+	Remaining time.Duration
+	ReadyAt   int64 // timestamp milidetik
+}
+
+func (e *CooldownError) Error() string {
+	return "cooldown! tunggu " + formatRemaining(e.Remaining) + " lagi"
+}
+
+// formatRemaining: "3 jam 20 menit", "4 menit 10 detik", atau "12 detik"
+func formatRemaining(d time.Duration) string {
+	total := (d.Milliseconds() + 999) / 1000 // dibulatkan ke atas
+	hours := total / 3600
+	minutes := total % 3600 / 60
+	seconds := total % 60
+	switch {
+	case hours > 0:
+		return fmt.Sprintf("%d jam %d menit", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%d menit %d detik", minutes, seconds)
+	default:
+		return fmt.Sprintf("%d detik", seconds)
+	}
+}
+
+// CooldownStatus adalah sisa cooldown satu aksi untuk satu user
+type CooldownStatus struct {
+	Action      string `json:"action"`
+	Field       string `json:"field"`
+	CooldownMs  int64  `json:"cooldownMs"`
+	RemainingMs int64  `json:"remainingMs"`
+	Ready       bool   `json:"ready"`
+	ReadyAt     int64  `json:"readyAt"`
+	Claim       bool   `json:"claim,omitempty"`
+}
+
+// isPremium: premiumTime adalah timestamp (ms) berakhirnya premium
+func isPremium(user *entity.User, now int64) bool {
+	return user.PremiumTime > float64(now)
+}
+
+// status menghitung sisa cooldown c untuk doc pada waktu now (ms)
+func (c Cooldown) status(doc map[string]interface{}, premium bool, now int64) CooldownStatus {
+	duration := c.Duration
+	if premium && c.PremiumMultiplier > 0 {
+		duration = time.Duration(float64(duration) * c.PremiumMultiplier)
+	}
+	last, _ := getPath(doc, c.Field)
+	lastMs, _ := last.(float64)
+
+	readyAt := int64(lastMs) + duration.Milliseconds()
+	if c.NextReady != nil && lastMs > 0 {
+		readyAt = c.NextReady(int64(lastMs))
+	}
+	remaining := max(readyAt-now, 0)
+	return CooldownStatus{
+		Action:      c.Action,
+		Field:       c.Field,
+		CooldownMs:  duration.Milliseconds(),
+		RemainingMs: remaining,
+		Ready:       remaining == 0,
+		ReadyAt:     max(readyAt, now),
+		Claim:       c.Claim,
+	}
+}
+
+// stampCooldown mengecek cooldown c di doc lalu mengisi field-nya dengan
+// now. *CooldownError kalau belum selesai (doc tidak diubah).
+func stampCooldown(doc map[string]interface{}, c Cooldown, premium bool, now int64) (CooldownStatus, error) {
+	st, err := c.check(doc, premium, now)
+	if err != nil {
+		return st, err
+	}
+	if err := setPath(doc, c.Field, float64(now)); err != nil {
+		return st, err
+	}
+	return c.status(doc, premium, now), nil
+}
+
+// check seperti status, tapi *CooldownError kalau belum selesai
+func (c Cooldown) check(doc map[string]interface{}, premium bool, now int64) (CooldownStatus, error) {
+	st := c.status(doc, premium, now)
+	if !st.Ready {
+		return st, &CooldownError{Action: c.Action, Remaining: time.Duration(st.RemainingMs) * time.Millisecond, ReadyAt: st.ReadyAt}
+	}
+	return st, nil
+}
+
+// withCooldownRecheck membuat cooldown c dicek ulang (pada waktu now) kalau
+// penulisan harus disusun ulang di atas dokumen yang ditulis pihak lain,
+// supaya klaim yang sama tidak tersimpan dua kali
+func withCooldownRecheck(ctx context.Context, c Cooldown, now int64) context.Context {
+	return repository.WithRebaseCheck(ctx, func(doc map[string]interface{}) error {
+		premiumTime, _ := getPath(doc, "premiumTime")
+		until, _ := premiumTime.(float64)
+		_, err := c.check(doc, until > float64(now), now)
+		return err
+	})
+}
+
+// UseCooldown mengecek lalu mencap cooldown action secara atomik (dalam
+// satu MutateUser)
+func (s *UserService) UseCooldown(ctx context.Context, userID, action string) (*CooldownStatus, error) {
+	c, ok := CooldownFor(action)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCooldown, action)
+	}
+
+	now := time.Now().UnixMilli()
+	ctx = withDefaultReason(ctx, "cooldown:"+action)
+	ctx = withCooldownRecheck(ctx, c, now)
+	var st CooldownStatus
+	_, _, err := s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		premium := isPremium(user, now)
+		return s.mutateAsMap(user, func(doc map[string]interface{}) error {
+			var err error
+			st, err = stampCooldown(doc, c, premium, now)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// GetCooldowns mengembalikan sisa cooldown setiap aksi di CooldownRegistry
+// dan setiap periode klaim
+func (s *UserService) GetCooldowns(ctx context.Context, userID string) ([]CooldownStatus, error) {
+	user, _, err := s.Repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now().UnixMilli()
+	premium := isPremium(user, now)
+	doc := user.ToMap()
+	statuses := make([]CooldownStatus, 0, len(CooldownRegistry)+len(s.Claims))
+	for _, c := range CooldownRegistry {
+		statuses = append(statuses, c.status(doc, premium, now))
+	}
+	for period, claim := range s.Claims {
+		statuses = append(statuses, claim.cooldown(period).status(doc, premium, now))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Action < statuses[j].Action })
+	return statuses, nil
+}
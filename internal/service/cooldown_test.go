@@ -0,0 +1,98 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCooldownStatus(t *testing.T) {
+	const now = int64(10_000_000)
+	cd := Cooldown{Action: "adventure", Field: "lastadventure", Duration: 5 * time.Minute, PremiumMultiplier: 0.5}
+	minute := time.Minute.Milliseconds()
+	tests := []struct {
+		name          string
+		last          float64
+		premium       bool
+		wantRemaining int64
+	}{
+		{"belum pernah", 0, false, 0},
+		{"baru saja", float64(now), false, 5 * minute},
+		{"sisa 2 menit", float64(now - 3*minute), false, 2 * minute},
+		{"premium separuh", float64(now - 2*minute), true, minute / 2},
+		{"premium sudah lewat", float64(now - 3*minute), true, 0},
+		{"sudah lewat", float64(now - 6*minute), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := cd.status(map[string]interface{}{"lastadventure": tt.last}, tt.premium, now)
+			if st.RemainingMs != tt.wantRemaining || st.Ready != (tt.wantRemaining == 0) || st.ReadyAt != now+tt.wantRemaining {
+				t.Errorf("status = %+v, mau sisa %d", st, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestStampCooldown(t *testing.T) {
+	const now = int64(10_000_000)
+	cd := Cooldown{Action: "adventure", Field: "lastadventure", Duration: time.Minute}
+
+	doc := map[string]interface{}{"lastadventure": float64(now - 30_000)}
+	_, err := stampCooldown(doc, cd, false, now)
+	var cooldownErr *CooldownError
+	if !errors.As(err, &cooldownErr) || cooldownErr.Remaining != 30*time.Second {
+		t.Fatalf("err = %v, mau *CooldownError sisa 30 detik", err)
+	}
+	if doc["lastadventure"] != float64(now-30_000) {
+		t.Errorf("doc diubah walau cooldown belum selesai: %v", doc["lastadventure"])
+	}
+
+	st, err := stampCooldown(doc, cd, false, now+30_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc["lastadventure"] != float64(now+30_000) || st.Ready {
+		t.Errorf("lastadventure = %v ready = %v", doc["lastadventure"], st.Ready)
+	}
+}
+
+func TestFormatRemaining(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{3*time.Hour + 20*time.Minute, "3 jam 20 menit"},
+		{4*time.Minute + 10*time.Second, "4 menit 10 detik"},
+		{12 * time.Second, "12 detik"},
+		{1500 * time.Millisecond, "2 detik"},
+	}
+	for _, tt := range tests {
+		if got := formatRemaining(tt.d); got != tt.want {
+			t.Errorf("formatRemaining(%v) = %q, mau %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestUseCooldown(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, "u1", func(u *entity.User) {
+		u.PremiumTime = float64(time.Now().Add(time.Hour).UnixMilli())
+	})
+
+	st, err := s.UseCooldown(ctx, "u1", "adventure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.CooldownMs != (150 * time.Second).Milliseconds() {
+		t.Errorf("cooldown premium = %dms", st.CooldownMs)
+	}
+	var cooldownErr *CooldownError
+	if _, err := s.UseCooldown(ctx, "u1", "adventure"); !errors.As(err, &cooldownErr) {
+		t.Errorf("pemakaian kedua = %v, mau *CooldownError", err)
+	}
+	if _, err := s.UseCooldown(ctx, "u1", "daily"); !errors.Is(err, ErrUnknownCooldown) {
+		t.Errorf("klaim lewat UseCooldown = %v, mau ErrUnknownCooldown", err)
+	}
+}
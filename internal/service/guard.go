@@ -0,0 +1,190 @@
+package service
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Guard adalah syarat yang harus terpenuhi sebelum operasi ditulis, misal
+// "money >= 5000", "jail.status == false", atau "lastmancing < now-300000".
+// Dievaluasi di dalam transaksi yang sama dengan operasinya.
+type Guard struct {
+	Expr  string
+	Path  string
+	Op    string
+	value guardValue
+}
+
+// guardValue adalah sisi kanan guard: literal atau waktu relatif ke "now"
+type guardValue struct {
+	literal  interface{}
+	relative bool  // true kalau bentuknya now / now-N / now+N
+	offset   int64 // dalam milidetik
+}
+
+// GuardError menandakan guard yang tidak terpenuhi (dokumen tidak diubah).
+// UserID hanya diisi untuk transaksi multi-user.
+type GuardError struct {
+	UserID string
+	Guard  string
+	Actual interface{}
+}
+
+func (e *GuardError) Error() string {
+	if e.UserID != "" {
+		return fmt.Sprintf("guard tidak terpenuhi untuk user %s: %s", e.UserID, e.Guard)
+	}
+	return fmt.Sprintf("guard tidak terpenuhi: %s", e.Guard)
+}
+
+// GuardSyntaxError menandakan ekspresi guard yang tidak bisa dibaca.
+// UserID hanya diisi untuk transaksi multi-user.
+type GuardSyntaxError struct {
+	UserID string
+	Expr   string
+	Reason string
+}
+
+func (e *GuardSyntaxError) Error() string {
+	if e.UserID != "" {
+		return fmt.Sprintf("guard '%s' untuk user %s tidak valid: %s", e.Expr, e.UserID, e.Reason)
+	}
+	return fmt.Sprintf("guard '%s' tidak valid: %s", e.Expr, e.Reason)
+}
+
+// urutan penting: operator dua karakter dicek dulu
+var guardOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// ParseGuards mengubah daftar ekspresi menjadi Guard
+func ParseGuards(exprs []string) ([]Guard, error) {
+	guards := make([]Guard, 0, len(exprs))
+	for _, expr := range exprs {
+		g, err := parseGuard(expr)
+		if err != nil {
+			return nil, err
+		}
+		guards = append(guards, g)
+	}
+	return guards, nil
+}
+
+func parseGuard(expr string) (Guard, error) {
+	// Operator = karakter operator pertama setelah path, jadi string di
+	// sisi kanan boleh mengandung "<" atau "=".
+	idx := strings.IndexAny(expr, "<>=!")
+	if idx >= 0 {
+		for _, op := range guardOperators {
+			if !strings.HasPrefix(expr[idx:], op) {
+				continue
+			}
+
+			path := strings.TrimSpace(expr[:idx])
+			rawValue := strings.TrimSpace(expr[idx+len(op):])
+			if !validPath(path) {
+				return Guard{}, &GuardSyntaxError{Expr: expr, Reason: "path tidak valid"}
+			}
+
+			value, err := parseGuardValue(rawValue)
+			if err != nil {
+				return Guard{}, &GuardSyntaxError{Expr: expr, Reason: err.Error()}
+			}
+			return Guard{Expr: expr, Path: path, Op: op, value: value}, nil
+		}
+	}
+	return Guard{}, &GuardSyntaxError{Expr: expr, Reason: "operator harus salah satu dari >=, <=, ==, !=, >, <"}
+}
+
+func parseGuardValue(raw string) (guardValue, error) {
+	switch raw {
+	case "":
+		return guardValue{}, fmt.Errorf("nilai kosong")
+	case "true":
+		return guardValue{literal: true}, nil
+	case "false":
+		return guardValue{literal: false}, nil
+	case "null":
+		return guardValue{literal: nil}, nil
+	case "now":
+		return guardValue{relative: true}, nil
+	}
+
+	// String dengan kutip ganda atau tunggal
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return guardValue{literal: raw[1 : len(raw)-1]}, nil
+	}
+
+	// now-300000 / now+60000
+	if strings.HasPrefix(raw, "now") {
+		rest := strings.ReplaceAll(raw[len("now"):], " ", "")
+		offset, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil || (rest[0] != '-' && rest[0] != '+') {
+			return guardValue{}, fmt.Errorf("format waktu harus now, now-N atau now+N (milidetik)")
+		}
+		return guardValue{relative: true, offset: offset}, nil
+	}
+
+	num, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return guardValue{}, fmt.Errorf("nilai '%s' harus angka, true/false, null, now±N, atau string berkutip", raw)
+	}
+	return guardValue{literal: num}, nil
+}
+
+// checkGuards mengembalikan GuardError untuk guard pertama yang gagal
+func checkGuards(doc map[string]interface{}, guards []Guard) error {
+	now := time.Now().UnixMilli()
+	for _, g := range guards {
+		actual, _ := getPath(doc, g.Path)
+		if !g.matches(actual, now) {
+			return &GuardError{Guard: g.Expr, Actual: actual}
+		}
+	}
+	return nil
+}
+
+// withGuardRecheck membuat guards dicek ulang kalau penulisan harus disusun
+// ulang di atas dokumen yang ditulis pihak lain (write-behind)
+func withGuardRecheck(ctx context.Context, guards []Guard) context.Context {
+	if len(guards) == 0 {
+		return ctx
+	}
+	return repository.WithRebaseCheck(ctx, func(doc map[string]interface{}) error {
+		return checkGuards(doc, guards)
+	})
+}
+
+func (g Guard) matches(actual interface{}, now int64) bool {
+	expected := g.value.literal
+	if g.value.relative {
+		expected = float64(now + g.value.offset)
+	}
+
+	switch g.Op {
+	case "==":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	}
+
+	// Perbandingan urutan hanya untuk angka
+	a, okA := actual.(float64)
+	b, okB := expected.(float64)
+	if !okA || !okB {
+		return false
+	}
+	switch g.Op {
+	case ">=":
+		return a >= b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case "<":
+		return a < b
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseGuards(t *testing.T) {
+	tests := []struct {
+		expr    string
+		path    string
+		op      string
+		wantErr bool
+	}{
+		{"money >= 100", "money", ">=", false},
+		{"rpg.level>5", "rpg.level", ">", false},
+		{"name == 'a<b'", "name", "==", false},
+		{`job != "Pengangguran"`, "job", "!=", false},
+		{"banned == false", "banned", "==", false},
+		{"lastDaily < now-86400000", "lastDaily", "<", false},
+		{"money", "", "", true},
+		{"money >= ", "", "", true},
+		{" >= 1", "", "", true},
+		{"money >= abc", "", "", true},
+		{"lastDaily < now*2", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			guards, err := ParseGuards([]string{tt.expr})
+			if tt.wantErr {
+				var syntaxErr *GuardSyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("err = %v, mau *GuardSyntaxError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g := guards[0]; g.Path != tt.path || g.Op != tt.op {
+				t.Errorf("guard = %s %s, mau %s %s", g.Path, g.Op, tt.path, tt.op)
+			}
+		})
+	}
+}
+
+func TestCheckGuards(t *testing.T) {
+	now := time.Now().UnixMilli()
+	doc := map[string]interface{}{
+		"money":     100.0,
+		"name":      "a<b",
+		"banned":    false,
+		"lastDaily": float64(now - 2*86400000),
+		"rpg":       map[string]interface{}{"level": 5.0},
+		"proposal":  nil,
+	}
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"money >= 100", true},
+		{"money > 100", false},
+		{"rpg.level == 5", true},
+		{"rpg.level != 5", false},
+		{"name == 'a<b'", true},
+		{"banned == false", true},
+		{"proposal == null", true},
+		{"tidakada == null", true},
+		{"lastDaily < now-86400000", true},
+		{"lastDaily > now-86400000", false},
+		{"name > 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			guards, err := ParseGuards([]string{tt.expr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = checkGuards(doc, guards)
+			var guardErr *GuardError
+			if tt.ok && err != nil || !tt.ok && !errors.As(err, &guardErr) {
+				t.Errorf("checkGuards = %v, mau lolos %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApplyOpsGuards(t *testing.T) {
+	tests := []struct {
+		name      string
+		ops       []UserOp
+		guards    []string
+		wantErr   interface{}
+		wantMoney float64
+	}{
+		{"tanpa guard", []UserOp{{Op: "dec", Path: "money", Value: 30.0}}, nil, nil, 70},
+		{"guard lolos", []UserOp{{Op: "dec", Path: "money", Value: 30.0}}, []string{"money >= 30"}, nil, 70},
+		{"guard gagal tidak menyimpan", []UserOp{{Op: "dec", Path: "money", Value: 300.0}}, []string{"money >= 300"}, new(*GuardError), 100},
+		{"guard salah tulis", []UserOp{{Op: "inc", Path: "money"}}, []string{"money >>= 1"}, new(*GuardSyntaxError), 100},
+		{"operasi gagal tidak menyimpan", []UserOp{{Op: "inc", Path: "money"}, {Op: "inc", Path: "username"}}, nil, new(*OpError), 100},
+		{"hasil melanggar schema", []UserOp{{Op: "dec", Path: "money", Value: 101.0}}, nil, new(*ValidationError), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, "u1", func(u *entity.User) { u.Money = 100 })
+			_, _, err := s.ApplyOps(context.Background(), "u1", tt.ops, tt.guards)
+			if tt.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil && !errors.As(err, tt.wantErr) {
+				t.Fatalf("err = %v, mau %T", err, tt.wantErr)
+			}
+			user, _, _ := s.Repo.GetUser(context.Background(), "u1")
+			if user.Money != tt.wantMoney {
+				t.Errorf("money = %v, mau %v", user.Money, tt.wantMoney)
+			}
+		})
+	}
+}
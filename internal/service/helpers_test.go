@@ -0,0 +1,39 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestService membuat UserService di atas MemoryUserRepository dengan
+// satu user bernilai default yang sudah diubah edit
+func newTestService(t *testing.T, userID string, edit func(u *entity.User)) *UserService {
+	t.Helper()
+	repo := repository.NewMemoryUserRepository()
+	user := entity.NewUser()
+	user.ID = userID
+	if edit != nil {
+		edit(user)
+	}
+	if _, err := repo.SaveUser(context.Background(), userID, user, 0); err != nil {
+		t.Fatal(err)
+	}
+	return NewUserService(repo)
+}
+
+// withLocal mengganti time.Local (APP_TIMEZONE) selama test
+func withLocal(t *testing.T, loc *time.Location) {
+	t.Helper()
+	prev := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = prev })
+}
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func msAt(y int, m time.Month, d, hour, minute int) int64 {
+	return time.Date(y, m, d, hour, minute, 0, 0, wib).UnixMilli()
+}
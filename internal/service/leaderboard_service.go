@@ -0,0 +1,163 @@
+package service
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrUnknownLeaderboard dikembalikan kalau type bukan field angka user
+var ErrUnknownLeaderboard = errors.New("tipe leaderboard tidak dikenal")
+
+// ErrInvalidCursor dikembalikan kalau cursor leaderboard rusak atau dibuat
+// untuk type/urutan lain
+var ErrInvalidCursor = errors.New("cursor leaderboard tidak valid")
+
+// leaderboardCursor adalah isi cursor (base64 JSON): entry terakhir halaman
+// sebelumnya beserta peringkatnya
+type leaderboardCursor struct {
+	Type   string  `json:"t"`
+	Asc    bool    `json:"a,omitempty"`
+	Value  float64 `json:"v"`
+	UserID string  `json:"id"`
+	Rank   int     `json:"r"`
+}
+
+func encodeLeaderboardCursor(c leaderboardCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeLeaderboardCursor(s string) (leaderboardCursor, error) {
+	var c leaderboardCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.UserID == "" || c.Rank < 1 {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// GetLeaderboard mengambil satu halaman leaderboard untuk lbType (money,
+// level, wealth, atau path field angka seperti rpg.exp). Halaman berikutnya
+// pakai offset atau cursor (nextCursor dari halaman sebelumnya, kosong kalau
+// sudah habis). Mengembalikan (data, total user, nextCursor, error).
+func (s *UserService) GetLeaderboard(ctx context.Context, lbType string, asc bool, limit, offset int, cursor string) ([]map[string]interface{}, int, string, error) {
+	if !repository.IsLeaderboardType(lbType) {
+		return nil, 0, "", fmt.Errorf("%w: %s", ErrUnknownLeaderboard, lbType)
+	}
+
+	q := repository.LeaderboardQuery{Type: lbType, Asc: asc, Limit: limit, Offset: offset}
+	rank := offset // peringkat entry sebelum halaman ini
+	if cursor != "" {
+		c, err := decodeLeaderboardCursor(cursor)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		if c.Type != lbType || c.Asc != asc {
+			return nil, 0, "", fmt.Errorf("%w: cursor untuk type/order lain", ErrInvalidCursor)
+		}
+		q.After = &repository.LeaderboardCursor{Value: c.Value, UserID: c.UserID}
+		rank = c.Rank + offset
+	}
+
+	totalUsers, err := s.Repo.CountUsers(ctx) // total data user nya
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	var entries []repository.LeaderboardEntry
+	if limit > 0 {
+		entries, err = s.Repo.GetLeaderboard(ctx, q)
+		if err != nil {
+			return nil, 0, "", err
+		}
+	}
+
+	var result []map[string]interface{}
+	for i, e := range entries {
+		result = append(result, leaderboardEntryMap(lbType, rank+i+1, e))
+	}
+
+	// Halaman penuh berarti mungkin masih ada lanjutannya
+	nextCursor := ""
+	if limit > 0 && len(entries) == limit {
+		last := entries[len(entries)-1]
+		nextCursor = encodeLeaderboardCursor(leaderboardCursor{
+			Type: lbType, Asc: asc, Value: last.Value, UserID: last.User.ID, Rank: rank + len(entries),
+		})
+	}
+	return result, totalUsers, nextCursor, nil
+}
+
+// GetLeaderboardRank mengembalikan peringkat userID di leaderboard lbType
+// beserta user tepat di atas dan di bawahnya (nil kalau tidak ada).
+// percentile adalah persen user lain yang ada di bawahnya (100 = peringkat
+// pertama, 0 = terakhir).
+func (s *UserService) GetLeaderboardRank(ctx context.Context, lbType string, asc bool, userID string) (map[string]interface{}, error) {
+	if !repository.IsLeaderboardType(lbType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLeaderboard, lbType)
+	}
+
+	pos, ahead, err := s.Repo.GetLeaderboardPosition(ctx, lbType, asc, userID)
+	if err != nil {
+		return nil, err
+	}
+	totalUsers, err := s.Repo.CountUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rank := ahead + 1
+
+	// Tetangga: halaman 1 entry setelah user, dan setelah user di urutan terbalik
+	below, err := s.Repo.GetLeaderboard(ctx, repository.LeaderboardQuery{Type: lbType, Asc: asc, Limit: 1, After: pos})
+	if err != nil {
+		return nil, err
+	}
+	above, err := s.Repo.GetLeaderboard(ctx, repository.LeaderboardQuery{Type: lbType, Asc: !asc, Limit: 1, After: pos})
+	if err != nil {
+		return nil, err
+	}
+
+	percentile := 100.0
+	if totalUsers > 1 {
+		percentile = math.Round(float64(totalUsers-rank)/float64(totalUsers-1)*10000) / 100
+	}
+	result := map[string]interface{}{
+		"userId":     userID,
+		"rank":       rank,
+		"value":      pos.Value,
+		"totalUsers": totalUsers,
+		"percentile": percentile,
+		"above":      nil,
+		"below":      nil,
+	}
+	if len(above) > 0 {
+		result["above"] = leaderboardEntryMap(lbType, rank-1, above[0])
+	}
+	if len(below) > 0 {
+		result["below"] = leaderboardEntryMap(lbType, rank+1, below[0])
+	}
+	return result, nil
+}
+
+func leaderboardEntryMap(lbType string, rank int, e repository.LeaderboardEntry) map[string]interface{} {
+	u := e.User
+	res := map[string]interface{}{
+		"rank":     rank,
+		"userId":   u.ID,
+		"username": u.Username,
+		"money":    u.Money,
+		"diamond":  u.Diamond,
+		"level":    u.Rpg.Level,
+		"value":    e.Value,
+	}
+
+	if lbType == "wealth" {
+		res["wealth"] = e.Value
+	}
+	return res
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// newLeaderboardService: money a=300, b=200, c=200, d=100, e=50
+func newLeaderboardService(t *testing.T) *UserService {
+	t.Helper()
+	repo := repository.NewMemoryUserRepository()
+	for id, money := range map[string]float64{"a": 300, "b": 200, "c": 200, "d": 100, "e": 50} {
+		user := entity.NewUser()
+		user.ID, user.Money = id, money
+		if _, err := repo.SaveUser(context.Background(), id, user, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewUserService(repo)
+}
+
+func TestLeaderboardCursorPagination(t *testing.T) {
+	s := newLeaderboardService(t)
+	tests := []struct {
+		name string
+		asc  bool
+		want []string
+	}{
+		// nilai seri (b, c) diurutkan userId searah urutan nilai
+		{"desc", false, []string{"a", "c", "b", "d", "e"}},
+		{"asc", true, []string{"e", "d", "b", "c", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			cursor := ""
+			for page := 0; page < 5; page++ {
+				data, total, next, err := s.GetLeaderboard(context.Background(), "money", tt.asc, 2, 0, cursor)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != 5 {
+					t.Errorf("total = %d", total)
+				}
+				for _, row := range data {
+					if row["rank"] != len(ids)+1 {
+						t.Errorf("rank %s = %v, mau %d", row["userId"], row["rank"], len(ids)+1)
+					}
+					ids = append(ids, row["userId"].(string))
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("urutan = %v, mau %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardCursorInvalid(t *testing.T) {
+	s := newLeaderboardService(t)
+	ctx := context.Background()
+	_, _, next, err := s.GetLeaderboard(ctx, "money", false, 2, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		lbType string
+		asc    bool
+		cursor string
+	}{
+		{"bukan base64", "money", false, "%%%"},
+		{"type lain", "level", false, next},
+		{"urutan lain", "money", true, next},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := s.GetLeaderboard(ctx, tt.lbType, tt.asc, 2, 0, tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, mau ErrInvalidCursor", err)
+			}
+		})
+	}
+	if _, _, _, err := s.GetLeaderboard(ctx, "username", false, 2, 0, ""); !errors.Is(err, ErrUnknownLeaderboard) {
+		t.Errorf("type bukan angka = %v, mau ErrUnknownLeaderboard", err)
+	}
+}
+
+func TestLeaderboardRank(t *testing.T) {
+	s := newLeaderboardService(t)
+	tests := []struct {
+		userID     string
+		asc        bool
+		rank       int
+		percentile float64
+		above      string
+		below      string
+	}{
+		{"a", false, 1, 100, "", "c"},
+		{"c", false, 2, 75, "a", "b"},
+		{"b", false, 3, 50, "c", "d"},
+		{"e", false, 5, 0, "d", ""},
+		{"e", true, 1, 100, "", "d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			res, err := s.GetLeaderboardRank(context.Background(), "money", tt.asc, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res["rank"] != tt.rank || res["percentile"] != tt.percentile {
+				t.Errorf("rank = %v percentile = %v", res["rank"], res["percentile"])
+			}
+			for key, want := range map[string]string{"above": tt.above, "below": tt.below} {
+				got := ""
+				if row, ok := res[key].(map[string]interface{}); ok {
+					got = row["userId"].(string)
+				}
+				if got != want {
+					t.Errorf("%s = %q, mau %q", key, got, want)
+				}
+			}
+		})
+	}
+	if _, err := s.GetLeaderboardRank(context.Background(), "money", false, "nobody"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user tidak ada = %v", err)
+	}
+}
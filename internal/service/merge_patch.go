@@ -0,0 +1,31 @@
+package service
+
+// ApplyMergePatch menerapkan patch ke target sesuai RFC 7396 (JSON Merge Patch).
+// - key dengan nilai null dihapus dari target
+// - object digabung secara rekursif (rpg, jail, motor, dll)
+// - nilai lain (angka, string, array) menimpa nilai lama
+// Target dimodifikasi langsung dan juga dikembalikan.
+func ApplyMergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+
+	for k, v := range patch {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+
+		patchObj, isObj := v.(map[string]interface{})
+		if !isObj {
+			target[k] = v
+			continue
+		}
+
+		// Kalau nilai lama bukan object, mulai dari object kosong
+		targetObj, _ := target[k].(map[string]interface{})
+		target[k] = ApplyMergePatch(targetObj, patchObj)
+	}
+
+	return target
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		target, patch map[string]interface{}
+		want          map[string]interface{}
+	}{
+		{"timpa nilai", map[string]interface{}{"money": 1.0}, map[string]interface{}{"money": 2.0},
+			map[string]interface{}{"money": 2.0}},
+		{"null menghapus", map[string]interface{}{"money": 1.0, "bank": 2.0}, map[string]interface{}{"bank": nil},
+			map[string]interface{}{"money": 1.0}},
+		{"object digabung",
+			map[string]interface{}{"rpg": map[string]interface{}{"level": 1.0, "exp": 5.0}},
+			map[string]interface{}{"rpg": map[string]interface{}{"exp": 9.0, "mana": nil}},
+			map[string]interface{}{"rpg": map[string]interface{}{"level": 1.0, "exp": 9.0}}},
+		{"object menimpa non-object", map[string]interface{}{"rpg": 3.0},
+			map[string]interface{}{"rpg": map[string]interface{}{"level": 2.0}},
+			map[string]interface{}{"rpg": map[string]interface{}{"level": 2.0}}},
+		{"array ditimpa utuh", map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"tags": []interface{}{"c"}},
+			map[string]interface{}{"tags": []interface{}{"c"}}},
+		{"target nil", nil, map[string]interface{}{"money": 1.0}, map[string]interface{}{"money": 1.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyMergePatch(tt.target, tt.patch); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyMergePatch = %v, mau %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		patch   map[string]interface{}
+		version int64
+		guards  []string
+		wantErr error
+		check   func(u *entity.User) bool
+	}{
+		{"merge nested", map[string]interface{}{"rpg": map[string]interface{}{"level": 9.0}}, repository.AnyVersion, nil, nil,
+			func(u *entity.User) bool { return u.Rpg.Level == 9 && u.Rpg.Health == 100 }},
+		{"null kembali default", map[string]interface{}{"money": nil}, repository.AnyVersion, nil, nil,
+			func(u *entity.User) bool { return u.Money == entity.NewUser().Money }},
+		{"If-Match cocok", map[string]interface{}{"money": 5.0}, 1, nil, nil,
+			func(u *entity.User) bool { return u.Money == 5 }},
+		{"If-Match basi", map[string]interface{}{"money": 5.0}, 2, nil, repository.ErrVersionConflict,
+			func(u *entity.User) bool { return u.Money == 100 }},
+		{"guard gagal", map[string]interface{}{"money": 5.0}, repository.AnyVersion, []string{"money < 50"}, nil,
+			func(u *entity.User) bool { return u.Money == 100 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, "u1", func(u *entity.User) { u.Money = 100 })
+			_, _, err := s.PatchUser(ctx, "u1", tt.patch, tt.version, tt.guards)
+			var guardErr *GuardError
+			switch {
+			case len(tt.guards) > 0:
+				if !errors.As(err, &guardErr) {
+					t.Fatalf("err = %v, mau *GuardError", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, mau %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+			user, _, _ := s.Repo.GetUser(ctx, "u1")
+			if !tt.check(user) {
+				t.Errorf("hasil patch salah: money=%v rpg=%+v", user.Money, user.Rpg)
+			}
+		})
+	}
+	if _, _, err := newTestService(t, "u1", nil).PatchUser(ctx, "nobody", map[string]interface{}{}, repository.AnyVersion, nil); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user tidak ada = %v", err)
+	}
+}
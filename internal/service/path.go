@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Helper untuk akses field dokumen user memakai dotted path, misal
+// "money", "rpg.exp", atau "motor.Bensin".
+
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// getPath mengambil nilai di path. ok false kalau path tidak ada.
+func getPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	parts := splitPath(path)
+	current := doc
+	for i, key := range parts {
+		val, exists := current[key]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return val, true
+		}
+		next, isObj := val.(map[string]interface{})
+		if !isObj {
+			return nil, false
+		}
+		current = next
+	}
+	return nil, false
+}
+
+// setPath mengisi nilai di path, membuat object perantara kalau belum ada.
+func setPath(doc map[string]interface{}, path string, value interface{}) error {
+	parts := splitPath(path)
+	current := doc
+	for i, key := range parts[:len(parts)-1] {
+		val, exists := current[key]
+		if !exists || val == nil {
+			next := make(map[string]interface{})
+			current[key] = next
+			current = next
+			continue
+		}
+		next, isObj := val.(map[string]interface{})
+		if !isObj {
+			return fmt.Errorf("'%s' bukan object", strings.Join(parts[:i+1], "."))
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// deletePath menghapus key di path (tidak error kalau memang tidak ada).
+func deletePath(doc map[string]interface{}, path string) {
+	parts := splitPath(path)
+	current := doc
+	for _, key := range parts[:len(parts)-1] {
+		next, isObj := current[key].(map[string]interface{})
+		if !isObj {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
+
+func validPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, part := range splitPath(path) {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
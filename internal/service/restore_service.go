@@ -0,0 +1,68 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+)
+
+// ErrSnapshotNotFound dikembalikan kalau tidak ada snapshot sebelum waktu restore
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// RestoreResult adalah hasil (atau rencana, kalau dry-run) restore dokumen
+type RestoreResult struct {
+	DryRun       bool                     `json:"dryRun"`
+	FromVersion  int64                    `json:"fromVersion"`
+	SnapshotTime int64                    `json:"snapshotTime"`
+	Version      int64                    `json:"version,omitempty"`
+	Changes      []repository.FieldChange `json:"changes"`
+	User         *entity.User             `json:"data,omitempty"`
+}
+
+// RestoreUser mengembalikan dokumen user ke kondisi pada waktu at (ms) memakai
+// snapshot terakhir sebelum waktu tersebut. Dengan dryRun, hanya diff terhadap
+// dokumen sekarang yang dikembalikan tanpa menulis apa pun.
+func (s *UserService) RestoreUser(ctx context.Context, userID string, at int64, dryRun bool) (*RestoreResult, error) {
+	snap, err := s.Repo.GetSnapshotAt(ctx, userID, at)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, ErrSnapshotNotFound
+	}
+
+	result := &RestoreResult{
+		DryRun:       dryRun,
+		FromVersion:  snap.Version,
+		SnapshotTime: snap.CreatedAt,
+	}
+
+	target := snap.User.ToMap()
+	if dryRun {
+		current, _, err := s.Repo.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		var currentDoc map[string]interface{}
+		if current != nil {
+			currentDoc = current.ToMap()
+		}
+		result.Changes = repository.DiffDocuments(currentDoc, target)
+		return result, nil
+	}
+
+	ctx = repository.WithReason(ctx, "restore")
+	user, version, err := s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		result.Changes = repository.DiffDocuments(user.ToMap(), target)
+		*user = *snap.User
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Version = version
+	result.User = user
+	return result, nil
+}
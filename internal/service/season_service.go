@@ -0,0 +1,241 @@
+package service
+
+import (
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSeason dikembalikan kalau data season atau parameter
+// leaderboard season tidak masuk akal
+var ErrInvalidSeason = errors.New("season tidak valid")
+
+// ErrSeasonNotFound dikembalikan kalau season tidak ada
+var ErrSeasonNotFound = errors.New("season tidak ditemukan")
+
+const (
+	defaultSeasonTopN = 100
+	maxSeasonTopN     = 1000
+)
+
+// type leaderboard season kalau admin tidak memilih
+var defaultSeasonTypes = []string{"money", "level", "wealth"}
+
+// SeasonService mengatur season leaderboard: membuat, memulai (catat
+// baseline), dan mengarsip hasil akhir lewat RunSeasons
+type SeasonService struct {
+	Repo  repository.SeasonStore
+	Users repository.UserStore
+
+	runMu   sync.Mutex
+	changed chan struct{}
+}
+
+func NewSeasonService(repo repository.SeasonStore, users repository.UserStore) *SeasonService {
+	return &SeasonService{Repo: repo, Users: users, changed: make(chan struct{}, 1)}
+}
+
+// Changed memberi sinyal setiap ada season baru, supaya scheduler
+// menghitung ulang waktu tunggunya
+func (s *SeasonService) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// CreateSeason memvalidasi lalu menyimpan season baru (status scheduled).
+// Season dimulai oleh scheduler lewat RunSeasons, yang dibangunkan lewat
+// Changed; season yang waktu mulainya sudah lewat dimulai saat itu juga
+// (baseline = nilai saat dimulai).
+func (s *SeasonService) CreateSeason(ctx context.Context, season *repository.Season) error {
+	if season.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidSeason)
+	}
+	if season.StartsAt <= 0 || season.EndsAt <= season.StartsAt {
+		return fmt.Errorf("%w: startsAt dan endsAt (timestamp milidetik) wajib diisi, endsAt setelah startsAt", ErrInvalidSeason)
+	}
+	if season.EndsAt <= time.Now().UnixMilli() {
+		return fmt.Errorf("%w: endsAt sudah lewat", ErrInvalidSeason)
+	}
+	if len(season.Types) == 0 {
+		season.Types = defaultSeasonTypes
+	}
+	seen := make(map[string]bool)
+	for _, lbType := range season.Types {
+		if !repository.IsLeaderboardType(lbType) {
+			return fmt.Errorf("%w: type '%s' bukan field angka user", ErrInvalidSeason, lbType)
+		}
+		if seen[lbType] {
+			return fmt.Errorf("%w: type '%s' dobel", ErrInvalidSeason, lbType)
+		}
+		seen[lbType] = true
+	}
+	if season.TopN == 0 {
+		season.TopN = defaultSeasonTopN
+	}
+	if season.TopN < 1 || season.TopN > maxSeasonTopN {
+		return fmt.Errorf("%w: topN harus 1-%d", ErrInvalidSeason, maxSeasonTopN)
+	}
+
+	if err := s.Repo.CreateSeason(ctx, season); err != nil {
+		return err
+	}
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ListSeasons mengambil semua season, terbaru dulu
+func (s *SeasonService) ListSeasons(ctx context.Context) ([]repository.Season, error) {
+	return s.Repo.ListSeasons(ctx)
+}
+
+// GetSeason mencari season dari id angka atau "current" (season active
+// yang paling baru mulai)
+func (s *SeasonService) GetSeason(ctx context.Context, id string) (*repository.Season, error) {
+	if id == "current" {
+		seasons, err := s.Repo.ListSeasons(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, season := range seasons {
+			if season.Status == repository.SeasonActive {
+				return &season, nil
+			}
+		}
+		return nil, ErrSeasonNotFound
+	}
+
+	seasonID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrSeasonNotFound
+	}
+	season, err := s.Repo.GetSeason(ctx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season == nil {
+		return nil, ErrSeasonNotFound
+	}
+	return season, nil
+}
+
+// GetSeasonLeaderboard mengambil peringkat season: hasil arsip untuk season
+// yang sudah selesai, atau peringkat live untuk season yang sedang berjalan.
+// kind "gained" (default) = kenaikan sejak season mulai, "total" = nilai.
+func (s *SeasonService) GetSeasonLeaderboard(ctx context.Context, id, lbType, kind string, limit, offset int) (map[string]interface{}, error) {
+	season, err := s.GetSeason(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lbType == "" {
+		lbType = season.Types[0]
+	}
+	if !containsString(season.Types, lbType) {
+		return nil, fmt.Errorf("%w: type '%s' tidak ada di season ini (pilihan: %v)", ErrInvalidSeason, lbType, season.Types)
+	}
+	if kind == "" {
+		kind = repository.SeasonGained
+	}
+	if kind != repository.SeasonGained && kind != repository.SeasonTotal {
+		return nil, fmt.Errorf("%w: kind harus gained atau total", ErrInvalidSeason)
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var data interface{} = []interface{}{}
+	switch season.Status {
+	case repository.SeasonEnded:
+		data, err = s.Repo.GetSeasonResults(ctx, season.ID, lbType, kind, limit, offset)
+	case repository.SeasonActive:
+		var entries []repository.LeaderboardEntry
+		if kind == repository.SeasonGained {
+			entries, err = s.Repo.GetSeasonGained(ctx, season.ID, lbType, limit, offset)
+		} else {
+			entries, err = s.Users.GetLeaderboard(ctx, repository.LeaderboardQuery{Type: lbType, Limit: limit, Offset: offset})
+		}
+		result := []map[string]interface{}{}
+		for i, e := range entries {
+			result = append(result, leaderboardEntryMap(lbType, offset+i+1, e))
+		}
+		data = result
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"season": season,
+		"type":   lbType,
+		"kind":   kind,
+		"live":   season.Status == repository.SeasonActive,
+		"data":   data,
+	}, nil
+}
+
+// RunSeasons memulai season yang waktunya sudah tiba dan mengarsip season
+// yang sudah selesai, lalu mengembalikan waktu mulai/selesai berikutnya
+// (zero kalau tidak ada). Season yang terlewat saat server mati diproses
+// begitu server hidup lagi (dengan nilai saat itu).
+func (s *SeasonService) RunSeasons(ctx context.Context, now time.Time) time.Time {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	seasons, err := s.Repo.ListSeasons(ctx)
+	if err != nil {
+		slog.Error("Gagal mengambil daftar season", "err", err)
+		return time.Time{}
+	}
+	nowMs := now.UnixMilli()
+	var next int64
+	for i := range seasons {
+		season := &seasons[i]
+		if season.Status == repository.SeasonScheduled && season.StartsAt <= nowMs {
+			if err := s.Repo.StartSeason(ctx, season); err != nil {
+				slog.Error("Gagal memulai season", "season", season.ID, "err", err)
+				continue
+			}
+			slog.Info("Season dimulai", "season", season.ID, "name", season.Name)
+		}
+		if season.Status == repository.SeasonActive && season.EndsAt <= nowMs {
+			if err := s.Repo.ArchiveSeason(ctx, season); err != nil {
+				slog.Error("Gagal mengarsip season", "season", season.ID, "err", err)
+				continue
+			}
+			slog.Info("Season selesai, hasil diarsip", "season", season.ID, "name", season.Name)
+		}
+
+		due := season.EndsAt
+		if season.Status == repository.SeasonScheduled {
+			due = season.StartsAt
+		}
+		if season.Status != repository.SeasonEnded && (next == 0 || due < next) {
+			next = due
+		}
+	}
+	if next == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(next)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateSeasonValidation(t *testing.T) {
+	now := time.Now().UnixMilli()
+	hour := time.Hour.Milliseconds()
+	tests := []struct {
+		name   string
+		season repository.Season
+		ok     bool
+	}{
+		{"valid", repository.Season{Name: "S1", StartsAt: now + hour, EndsAt: now + 2*hour}, true},
+		{"tanpa nama", repository.Season{StartsAt: now + hour, EndsAt: now + 2*hour}, false},
+		{"endsAt sebelum startsAt", repository.Season{Name: "S1", StartsAt: now + 2*hour, EndsAt: now + hour}, false},
+		{"sudah selesai", repository.Season{Name: "S1", StartsAt: now - 2*hour, EndsAt: now - hour}, false},
+		{"type bukan angka", repository.Season{Name: "S1", StartsAt: now + hour, EndsAt: now + 2*hour, Types: []string{"username"}}, false},
+		{"type dobel", repository.Season{Name: "S1", StartsAt: now + hour, EndsAt: now + 2*hour, Types: []string{"money", "money"}}, false},
+		{"topN terlalu besar", repository.Season{Name: "S1", StartsAt: now + hour, EndsAt: now + 2*hour, TopN: maxSeasonTopN + 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := repository.NewMemoryUserRepository()
+			s := NewSeasonService(repository.NewMemorySeasonRepository(users), users)
+			err := s.CreateSeason(context.Background(), &tt.season)
+			if tt.ok && err != nil || !tt.ok && !errors.Is(err, ErrInvalidSeason) {
+				t.Errorf("CreateSeason = %v, mau lolos %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSeasonBaselineAndArchive(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	setMoney := func(id string, money float64) {
+		t.Helper()
+		user, version, _ := users.GetUser(ctx, id)
+		if user == nil {
+			user = entity.NewUser()
+			user.ID = id
+		}
+		user.Money = money
+		if _, err := users.SaveUser(ctx, id, user, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setMoney("a", 1000)
+	setMoney("b", 100)
+
+	s := NewSeasonService(repository.NewMemorySeasonRepository(users), users)
+	now := time.Now()
+	season := &repository.Season{Name: "S1", Types: []string{"money"}, TopN: 10,
+		StartsAt: now.Add(-time.Minute).UnixMilli(), EndsAt: now.Add(time.Hour).UnixMilli()}
+	if err := s.CreateSeason(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	if season.Status != repository.SeasonScheduled {
+		t.Fatalf("status = %s, mau scheduled sampai dimulai scheduler", season.Status)
+	}
+	select {
+	case <-s.Changed():
+	default:
+		t.Fatal("scheduler tidak diberi sinyal season baru")
+	}
+	s.RunSeasons(ctx, now)
+	if current, err := s.GetSeason(ctx, "current"); err != nil || current.ID != season.ID {
+		t.Fatalf("current = %v, %v", current, err)
+	}
+
+	// b naik 500, a tetap, c daftar setelah season mulai (baseline default)
+	setMoney("b", 600)
+	setMoney("c", entity.NewUser().Money+50)
+
+	live, err := s.GetSeasonLeaderboard(ctx, "current", "money", "", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGained := []struct {
+		id    string
+		value float64
+	}{{"b", 500}, {"c", 50}, {"a", 0}}
+	rows := live["data"].([]map[string]interface{})
+	if len(rows) != len(wantGained) {
+		t.Fatalf("data live = %v", rows)
+	}
+	for i, want := range wantGained {
+		if rows[i]["userId"] != want.id || rows[i]["value"] != want.value {
+			t.Errorf("peringkat %d = %v %v, mau %s %v", i+1, rows[i]["userId"], rows[i]["value"], want.id, want.value)
+		}
+	}
+
+	if next := s.RunSeasons(ctx, now.Add(2*time.Hour)); !next.IsZero() {
+		t.Errorf("next = %v, mau tidak ada season lagi", next)
+	}
+	setMoney("a", 5000) // setelah diarsip tidak mengubah hasil
+
+	tests := []struct {
+		kind  string
+		first string
+		value float64
+	}{
+		{repository.SeasonGained, "b", 500},
+		{repository.SeasonTotal, "c", entity.NewUser().Money + 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			res, err := s.GetSeasonLeaderboard(ctx, "1", "money", tt.kind, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := res["data"].([]repository.SeasonResult)
+			if res["live"] != false || len(results) != 3 || results[0].UserID != tt.first || results[0].Value != tt.value {
+				t.Errorf("hasil arsip = %v", results)
+			}
+		})
+	}
+	if _, err := s.GetSeason(ctx, "current"); !errors.Is(err, ErrSeasonNotFound) {
+		t.Errorf("current setelah selesai = %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// StreakPeriod adalah periode klaim yang menghitung streak (dailyStreak)
+const StreakPeriod = "daily"
+
+// Harga dan batas stok item streakfreeze
+const (
+	StreakFreezePrice = 25 // diamond
+	MaxStreakFreeze   = 3
+)
+
+// StreakTier: mulai dailyStreak MinDays, hadiah daily dikali Multiplier lalu
+// ditambah Bonus
+type StreakTier struct {
+	MinDays    int                `json:"minDays"`
+	Multiplier float64            `json:"multiplier"`
+	Bonus      map[string]float64 `json:"bonus,omitempty"`
+}
+
+// defaultStreakTiers dipakai DefaultClaims untuk klaim daily
+var defaultStreakTiers = []StreakTier{
+	{MinDays: 1, Multiplier: 1},
+	{MinDays: 3, Multiplier: 1.5},
+	{MinDays: 7, Multiplier: 2, Bonus: map[string]float64{"diamond": 2}},
+	{MinDays: 14, Multiplier: 2.5, Bonus: map[string]float64{"diamond": 5}},
+	{MinDays: 30, Multiplier: 3, Bonus: map[string]float64{"diamond": 10, "legendary": 1}},
+}
+
+// validateStreakTiers: minDays naik berurutan mulai dari 1 ke atas
+func validateStreakTiers(period string, tiers []StreakTier) error {
+	if len(tiers) == 0 {
+		return nil
+	}
+	if period != StreakPeriod {
+		return fmt.Errorf("periode '%s': streakTiers hanya untuk periode '%s'", period, StreakPeriod)
+	}
+	prev := 0
+	for _, tier := range tiers {
+		if tier.MinDays <= prev {
+			return fmt.Errorf("periode '%s': minDays streakTiers harus mulai dari 1 dan urut naik", period)
+		}
+		if tier.Multiplier <= 0 {
+			return fmt.Errorf("periode '%s': multiplier streak %d hari harus lebih dari 0", period, tier.MinDays)
+		}
+		for path, amount := range tier.Bonus {
+			if !entity.IsNumericPath(path) || amount <= 0 {
+				return fmt.Errorf("periode '%s': bonus streak '%s' harus field angka user dan lebih dari 0", period, path)
+			}
+		}
+		prev = tier.MinDays
+	}
+	return nil
+}
+
+// tierFor mengembalikan tier tertinggi yang sudah dicapai streak (nil kalau
+// belum ada) dan tier berikutnya (nil kalau sudah tertinggi)
+func tierFor(tiers []StreakTier, streak int) (current, next *StreakTier) {
+	for i := range tiers {
+		if tiers[i].MinDays > streak {
+			return current, &tiers[i]
+		}
+		current = &tiers[i]
+	}
+	return current, nil
+}
+
+// StreakResult adalah perubahan streak saat klaim daily
+type StreakResult struct {
+	Streak      int         `json:"streak"`
+	Best        int         `json:"best"`
+	MissedDays  int         `json:"missedDays"`
+	FreezesUsed int         `json:"freezesUsed"`
+	Reset       bool        `json:"reset"`
+	Multiplier  float64     `json:"multiplier"`
+	Tier        *StreakTier `json:"tier"`
+	NextTier    *StreakTier `json:"nextTier"`
+}
+
+// dayNumber adalah nomor hari kalender timestamp ms menurut time.Local
+// (APP_TIMEZONE), aman untuk pergantian DST
+func dayNumber(ms int64) int64 {
+	y, m, d := time.UnixMilli(ms).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// streakGap menghitung jumlah hari yang terlewat di antara klaim terakhir
+// (lastMs) dan now menurut hari reset claim: 0 kalau kemarin, -1 kalau hari
+// yang sama
+func streakGap(claim ClaimPeriod, lastMs float64, now int64) int {
+	return int(claim.dayOf(now) - claim.dayOf(int64(lastMs)) - 1)
+}
+
+// advanceStreak menghitung streak baru di doc untuk klaim daily pada now.
+// Harus dipanggil sebelum field klaim dicap. Hari yang terlewat ditutup
+// streakfreeze kalau stoknya cukup, kalau tidak streak mulai lagi dari 1.
+func advanceStreak(doc map[string]interface{}, claim ClaimPeriod, now int64) (*StreakResult, error) {
+	last, _ := getPath(doc, claim.Field)
+	lastMs, _ := last.(float64)
+	streakVal, _ := getPath(doc, "dailyStreak")
+	bestVal, _ := getPath(doc, "bestDailyStreak")
+	freezeVal, _ := getPath(doc, "streakfreeze")
+	streak, _ := streakVal.(float64)
+	best, _ := bestVal.(float64)
+	freezes, _ := freezeVal.(float64)
+
+	res := &StreakResult{Streak: int(streak)}
+	gap := streakGap(claim, lastMs, now)
+	switch {
+	case lastMs <= 0 || streak <= 0:
+		res.Streak = 1
+	case gap < 0:
+		// klaim lagi di hari yang sama (cooldown lebih pendek dari sehari)
+	case gap == 0:
+		res.Streak++
+	case float64(gap) <= freezes:
+		res.MissedDays, res.FreezesUsed = gap, gap
+		res.Streak++
+	default:
+		res.MissedDays, res.Reset = gap, true
+		res.Streak = 1
+	}
+	res.Best = max(res.Streak, int(best))
+
+	for path, value := range map[string]float64{
+		"dailyStreak":     float64(res.Streak),
+		"bestDailyStreak": float64(res.Best),
+		"streakfreeze":    freezes - float64(res.FreezesUsed),
+	} {
+		if err := setPath(doc, path, value); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Multiplier = 1
+	res.Tier, res.NextTier = tierFor(claim.StreakTiers, res.Streak)
+	if res.Tier != nil {
+		res.Multiplier = res.Tier.Multiplier
+	}
+	return res, nil
+}
+
+// streakRewards adalah hadiah klaim setelah dikali multiplier tier streak
+// dan ditambah bonusnya
+func streakRewards(rewards map[string]float64, st *StreakResult) map[string]float64 {
+	result := make(map[string]float64, len(rewards))
+	for path, amount := range rewards {
+		result[path] = math.Floor(amount * st.Multiplier)
+	}
+	if st.Tier != nil {
+		for path, amount := range st.Tier.Bonus {
+			result[path] += amount
+		}
+	}
+	return result
+}
+
+// message: "Streak 7 hari (x2)." plus catatan freeze atau streak putus
+func (st *StreakResult) message() string {
+	msg := fmt.Sprintf("Streak %d hari (x%g).", st.Streak, st.Multiplier)
+	switch {
+	case st.Reset:
+		msg = fmt.Sprintf("Streak putus karena bolos %d hari, mulai lagi dari 1 hari.", st.MissedDays)
+	case st.FreezesUsed > 0:
+		msg += fmt.Sprintf(" %d streak freeze terpakai untuk %d hari yang terlewat.", st.FreezesUsed, st.MissedDays)
+	}
+	if st.NextTier != nil {
+		msg += fmt.Sprintf(" %d hari lagi menuju x%g.", st.NextTier.MinDays-st.Streak, st.NextTier.Multiplier)
+	}
+	return msg
+}
+
+// GetStreak mengembalikan status streak daily user. broken berarti streak
+// akan mulai lagi dari 1 di klaim berikutnya (hari terlewat melebihi stok
+// streakfreeze).
+func (s *UserService) GetStreak(ctx context.Context, userID string) (map[string]interface{}, error) {
+	user, _, err := s.Repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	claim, ok := s.Claims[StreakPeriod]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownClaim, StreakPeriod)
+	}
+
+	now := time.Now().UnixMilli()
+	doc := user.ToMap()
+	last, _ := getPath(doc, claim.Field)
+	lastMs, _ := last.(float64)
+	streak := int(user.DailyStreak)
+	missed := 0
+	if lastMs > 0 {
+		missed = max(streakGap(claim, lastMs, now), 0)
+	}
+	broken := streak > 0 && float64(missed) > user.StreakFreeze
+
+	nextClaimAt := now
+	if lastMs > 0 {
+		nextClaimAt = claim.cooldown(StreakPeriod).status(doc, isPremium(user, now), now).ReadyAt
+	}
+	current, next := tierFor(claim.StreakTiers, streak)
+	return map[string]interface{}{
+		"userId":       userID,
+		"streak":       streak,
+		"best":         int(user.BestDailyStreak),
+		"streakFreeze": int(user.StreakFreeze),
+		"missedDays":   missed,
+		"broken":       broken,
+		"claimedToday": lastMs > 0 && claim.dayOf(int64(lastMs)) == claim.dayOf(now),
+		"nextClaimAt":  nextClaimAt,
+		"tier":         current,
+		"nextTier":     next,
+		"tiers":        claim.StreakTiers,
+		"freezePrice":  StreakFreezePrice,
+		"maxFreeze":    MaxStreakFreeze,
+	}, nil
+}
+
+// BuyStreakFreeze menukar StreakFreezePrice diamond dengan 1 streakfreeze
+// (maksimal MaxStreakFreeze). Diamond kurang atau stok penuh dikembalikan
+// sebagai *GuardError.
+func (s *UserService) BuyStreakFreeze(ctx context.Context, userID string) (*entity.User, error) {
+	ctx = withDefaultReason(ctx, "buy:streakfreeze")
+	ctx = repository.WithRebaseCheck(ctx, canBuyStreakFreeze)
+	user, _, err := s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		return s.mutateAsMap(user, func(doc map[string]interface{}) error {
+			if err := canBuyStreakFreeze(doc); err != nil {
+				return err
+			}
+			return applyOps(doc, []UserOp{
+				{Op: "dec", Path: "diamond", Value: float64(StreakFreezePrice)},
+				{Op: "inc", Path: "streakfreeze", Value: 1.0},
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// canBuyStreakFreeze: diamond cukup dan stok streakfreeze belum penuh
+func canBuyStreakFreeze(doc map[string]interface{}) error {
+	diamond, _ := getPath(doc, "diamond")
+	if d, ok := diamond.(float64); !ok || d < StreakFreezePrice {
+		return &GuardError{Guard: fmt.Sprintf("diamond >= %d", StreakFreezePrice), Actual: diamond}
+	}
+	stock, _ := getPath(doc, "streakfreeze")
+	if n, ok := stock.(float64); ok && n >= MaxStreakFreeze {
+		return &GuardError{Guard: fmt.Sprintf("streakfreeze < %d", MaxStreakFreeze), Actual: stock}
+	}
+	return nil
+}
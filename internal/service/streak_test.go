@@ -0,0 +1,65 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"testing"
+	"time"
+)
+
+// Streak dihitung per hari kalender APP_TIMEZONE, termasuk untuk klaim
+// daily dengan reset rolling
+func TestAdvanceStreakCalendarDay(t *testing.T) {
+	withLocal(t, wib)
+	claim := DefaultClaims[StreakPeriod]
+
+	tests := []struct {
+		name       string
+		last, now  int64
+		streak     float64
+		freeze     float64
+		wantStreak int
+		wantReset  bool
+	}{
+		{"besoknya", msAt(2026, 3, 1, 20, 0), msAt(2026, 3, 2, 21, 0), 4, 0, 5, false},
+		{"23:59 lalu lusa 00:01 tanpa freeze", msAt(2026, 3, 1, 23, 59), msAt(2026, 3, 3, 0, 1), 4, 0, 1, true},
+		{"bolos sehari ditutup freeze", msAt(2026, 3, 1, 23, 59), msAt(2026, 3, 3, 0, 1), 4, 1, 5, false},
+		{"lewat akhir bulan", msAt(2026, 2, 28, 8, 0), msAt(2026, 3, 1, 9, 0), 6, 0, 7, false},
+		{"belum pernah klaim", 0, msAt(2026, 3, 1, 9, 0), 0, 0, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := map[string]interface{}{
+				"lastDaily":    float64(tt.last),
+				"dailyStreak":  tt.streak,
+				"streakfreeze": tt.freeze,
+			}
+			res, err := advanceStreak(doc, claim, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Streak != tt.wantStreak || res.Reset != tt.wantReset {
+				t.Errorf("streak = %d reset = %v, mau %d %v", res.Streak, res.Reset, tt.wantStreak, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestClaimDailyStreakRewards(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).UnixMilli()
+	s := newTestService(t, "u1", func(u *entity.User) {
+		u.LastDaily = float64(yesterday)
+		u.DailyStreak = 6
+	})
+
+	res, err := s.Claim(context.Background(), "u1", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Streak.Streak != 7 || res.Rewards["money"] != 20000 || res.Rewards["diamond"] != 4 {
+		t.Errorf("streak=%d rewards=%v", res.Streak.Streak, res.Rewards)
+	}
+	if res.User.DailyStreak != 7 || res.User.BestDailyStreak != 7 {
+		t.Errorf("dailyStreak=%v best=%v", res.User.DailyStreak, res.User.BestDailyStreak)
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// UserTxOps adalah operasi + guard untuk satu user di dalam POST /tx
+type UserTxOps struct {
+	Ops    []UserOp `json:"ops"`
+	Guards []string `json:"guards"`
+}
+
+// ErrInvalidTransfer dikembalikan kalau permintaan transfer tidak masuk akal
+var ErrInvalidTransfer = errors.New("transfer tidak valid")
+
+// TransferItems adalah item yang boleh ditransfer selain field mata uang
+// (repository.LedgerFields). Field lain (exp, level, cooldown, durability,
+// dll) tidak bisa dipindahkan lewat transfer.
+var TransferItems = []string{
+	"potion", "limit", "streakfreeze",
+	"common", "uncommon", "mythic", "legendary",
+	"kayu", "batu", "string", "umpan", "rock", "wood", "iron", "emas",
+	"berlian", "coal", "kardus", "botol", "kaleng", "sampah",
+	"makanan", "bandage", "sushi", "roti", "ramuan", "soda", "vodka", "esteh",
+	"ikan", "udang", "lele", "nila", "bawal", "kepiting", "gurita", "cumi",
+	"buntal", "dory", "lumba", "lobster", "hiu", "orca", "paus",
+}
+
+// transferable melaporkan apakah field boleh dipindahkan lewat Transfer
+func transferable(field string) bool {
+	return slices.Contains(repository.LedgerFields, field) || slices.Contains(TransferItems, field)
+}
+
+// Transfer memindahkan amount pada field (default money) dari satu user ke
+// user lain secara atomik dan mencatatnya di tabel transfers. Saldo pengirim
+// yang kurang dikembalikan sebagai GuardError.
+func (s *UserService) Transfer(ctx context.Context, t repository.Transfer) (map[string]interface{}, error) {
+	if t.Field == "" {
+		t.Field = "money"
+	}
+	switch {
+	case t.FromID == "" || t.ToID == "":
+		return nil, fmt.Errorf("%w: from dan to wajib diisi", ErrInvalidTransfer)
+	case t.FromID == t.ToID:
+		return nil, fmt.Errorf("%w: tidak bisa transfer ke diri sendiri", ErrInvalidTransfer)
+	case t.Amount <= 0:
+		return nil, fmt.Errorf("%w: amount harus lebih dari 0", ErrInvalidTransfer)
+	case !transferable(t.Field):
+		return nil, fmt.Errorf("%w: field %s tidak bisa ditransfer", ErrInvalidTransfer, t.Field)
+	}
+
+	ctx = repository.WithReason(ctx, "transfer")
+	users, err := s.Repo.MutateUsers(ctx, []string{t.FromID, t.ToID}, func(users map[string]*entity.User) error {
+		err := s.mutateAsMap(users[t.FromID], func(from map[string]interface{}) error {
+			balance, _ := getPath(from, t.Field)
+			if b, ok := balance.(float64); !ok || b < t.Amount {
+				return &GuardError{Guard: fmt.Sprintf("%s >= %.0f", t.Field, t.Amount), Actual: balance}
+			}
+			return applyOps(from, []UserOp{{Op: "dec", Path: t.Field, Value: t.Amount}})
+		})
+		if err != nil {
+			return withUserID(err, t.FromID)
+		}
+
+		err = s.mutateAsMap(users[t.ToID], func(to map[string]interface{}) error {
+			return applyOps(to, []UserOp{{Op: "inc", Path: t.Field, Value: t.Amount}})
+		})
+		return withUserID(err, t.ToID)
+	}, []repository.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+
+	fromBalance, _ := getPath(users[t.FromID].ToMap(), t.Field)
+	toBalance, _ := getPath(users[t.ToID].ToMap(), t.Field)
+	return map[string]interface{}{
+		"status":  true,
+		"message": fmt.Sprintf("Berhasil transfer %.0f %s dari %s ke %s.", t.Amount, t.Field, t.FromID, t.ToID),
+		"field":   t.Field,
+		"amount":  t.Amount,
+		"from":    map[string]interface{}{"userId": t.FromID, "balance": fromBalance},
+		"to":      map[string]interface{}{"userId": t.ToID, "balance": toBalance},
+	}, nil
+}
+
+// ApplyTx menjalankan operasi untuk beberapa user dalam satu transaksi. Semua
+// guard dicek dulu; kalau ada yang gagal, tidak ada user yang berubah.
+func (s *UserService) ApplyTx(ctx context.Context, users map[string]UserTxOps) (map[string]*entity.User, error) {
+	if len(users) == 0 {
+		return nil, &OpError{Reason: "daftar user kosong"}
+	}
+
+	userIDs := make([]string, 0, len(users))
+	guards := make(map[string][]Guard, len(users))
+	for userID, u := range users {
+		if err := validateOps(u.Ops); err != nil {
+			return nil, withUserID(err, userID)
+		}
+		parsed, err := ParseGuards(u.Guards)
+		if err != nil {
+			return nil, withUserID(err, userID)
+		}
+		guards[userID] = parsed
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	ctx = withDefaultReason(ctx, "tx")
+	return s.Repo.MutateUsers(ctx, userIDs, func(loaded map[string]*entity.User) error {
+		docs := make(map[string]map[string]interface{}, len(loaded))
+		for _, userID := range userIDs {
+			docs[userID] = loaded[userID].ToMap()
+			if err := checkGuards(docs[userID], guards[userID]); err != nil {
+				return withUserID(err, userID)
+			}
+		}
+		for _, userID := range userIDs {
+			if err := applyOps(docs[userID], users[userID].Ops); err != nil {
+				return withUserID(err, userID)
+			}
+			updated, err := s.decodeUserMap(docs[userID], loaded[userID].ToMap())
+			if err != nil {
+				return withUserID(err, userID)
+			}
+			*loaded[userID] = *updated
+		}
+		return nil
+	}, nil)
+}
+
+// withUserID menandai OpError / GuardError / GuardSyntaxError /
+// ValidationError dengan user yang bersangkutan
+func withUserID(err error, userID string) error {
+	var opErr *OpError
+	var guardErr *GuardError
+	var syntaxErr *GuardSyntaxError
+	var validationErr *ValidationError
+	if errors.As(err, &opErr) {
+		opErr.UserID = userID
+	} else if errors.As(err, &guardErr) {
+		guardErr.UserID = userID
+	} else if errors.As(err, &syntaxErr) {
+		syntaxErr.UserID = userID
+	} else if errors.As(err, &validationErr) {
+		validationErr.UserID = userID
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"Berpg/internal/repository"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTransferField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		wantErr error
+	}{
+		{"default money", "", nil},
+		{"mata uang", "diamond", nil},
+		{"item", "potion", nil},
+		{"exp ditolak", "rpg.exp", ErrInvalidTransfer},
+		{"cooldown ditolak", "lastDaily", ErrInvalidTransfer},
+		{"durability ditolak", "sworddurability", ErrInvalidTransfer},
+		{"path salah", "money..x", ErrInvalidTransfer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, "a", func(u *entity.User) { u.Diamond, u.Potion = 5, 5 })
+			if _, err := s.Repo.SaveUser(context.Background(), "b", entity.NewUser(), 0); err != nil {
+				t.Fatal(err)
+			}
+			_, err := s.Transfer(context.Background(), repository.Transfer{FromID: "a", ToID: "b", Field: tt.field, Amount: 1})
+			if tt.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, mau %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"fmt"
+)
+
+// UserOp adalah satu operasi atomik terhadap field user (dotted path).
+//   - inc / dec : tambah / kurangi angka (default 1), field kosong dianggap 0
+//   - set       : isi nilai apa saja
+//   - min / max : isi nilai hanya kalau lebih kecil / lebih besar dari nilai lama
+//   - unset     : hapus field
+type UserOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// OpError menandakan operasi yang tidak valid atau tidak bisa diterapkan.
+// UserID hanya diisi untuk transaksi multi-user.
+type OpError struct {
+	UserID string
+	Index  int
+	Op     string
+	Path   string
+	Reason string
+}
+
+func (e *OpError) Error() string {
+	msg := fmt.Sprintf("operasi #%d (%s %s): %s", e.Index, e.Op, e.Path, e.Reason)
+	if e.UserID != "" {
+		msg = "user " + e.UserID + ", " + msg
+	}
+	return msg
+}
+
+// ApplyOps menjalankan semua operasi dalam satu transaksi. Guard dicek dulu
+// terhadap dokumen terbaru di dalam transaksi yang sama; kalau ada guard
+// yang gagal (GuardError) atau satu operasi gagal, tidak ada yang tersimpan.
+func (s *UserService) ApplyOps(ctx context.Context, userID string, ops []UserOp, guardExprs []string) (*entity.User, int64, error) {
+	if err := validateOps(ops); err != nil {
+		return nil, 0, err
+	}
+	guards, err := ParseGuards(guardExprs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx = withDefaultReason(ctx, "ops")
+	ctx = withGuardRecheck(ctx, guards)
+	return s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		return s.mutateAsMap(user, func(doc map[string]interface{}) error {
+			if err := checkGuards(doc, guards); err != nil {
+				return err
+			}
+			return applyOps(doc, ops)
+		})
+	})
+}
+
+func validateOps(ops []UserOp) error {
+	if len(ops) == 0 {
+		return &OpError{Index: 0, Reason: "daftar operasi kosong"}
+	}
+
+	for i, op := range ops {
+		if !validPath(op.Path) {
+			return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: "path tidak valid"}
+		}
+
+		switch op.Op {
+		case "inc", "dec":
+			if op.Value == nil {
+				continue
+			}
+			if _, ok := op.Value.(float64); !ok {
+				return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: "value harus angka"}
+			}
+		case "min", "max":
+			if _, ok := op.Value.(float64); !ok {
+				return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: "value harus angka"}
+			}
+		case "set", "unset":
+		default:
+			return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: "operasi tidak dikenal (inc, dec, set, min, max, unset)"}
+		}
+	}
+	return nil
+}
+
+// applyOps menerapkan operasi (yang sudah divalidasi) ke dokumen
+func applyOps(doc map[string]interface{}, ops []UserOp) error {
+	for i, op := range ops {
+		if op.Op == "unset" {
+			deletePath(doc, op.Path)
+			continue
+		}
+		if op.Op == "set" {
+			if err := setPath(doc, op.Path, op.Value); err != nil {
+				return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: err.Error()}
+			}
+			continue
+		}
+
+		// Operasi angka
+		current, exists := getPath(doc, op.Path)
+		currentNum, isNum := current.(float64)
+		if exists && current != nil && !isNum {
+			return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: "nilai lama bukan angka"}
+		}
+
+		value := 1.0
+		if v, ok := op.Value.(float64); ok {
+			value = v
+		}
+
+		next := currentNum
+		switch op.Op {
+		case "inc":
+			next = currentNum + value
+		case "dec":
+			next = currentNum - value
+		case "min":
+			if !isNum || value < currentNum {
+				next = value
+			}
+		case "max":
+			if !isNum || value > currentNum {
+				next = value
+			}
+		}
+
+		if err := setPath(doc, op.Path, next); err != nil {
+			return &OpError{Index: i, Op: op.Op, Path: op.Path, Reason: err.Error()}
+		}
+	}
+	return nil
+}
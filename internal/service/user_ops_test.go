@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	doc := map[string]interface{}{"money": 1.0, "rpg": map[string]interface{}{"level": 2.0}}
+
+	if v, ok := getPath(doc, "rpg.level"); !ok || v != 2.0 {
+		t.Errorf("getPath rpg.level = %v %v", v, ok)
+	}
+	if _, ok := getPath(doc, "money.x"); ok {
+		t.Error("getPath money.x harusnya tidak ada")
+	}
+	if err := setPath(doc, "motor.Bensin", 5.0); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := getPath(doc, "motor.Bensin"); v != 5.0 {
+		t.Errorf("setPath membuat object perantara: %v", doc["motor"])
+	}
+	if err := setPath(doc, "money.x", 1.0); err == nil {
+		t.Error("setPath ke dalam angka harusnya error")
+	}
+	deletePath(doc, "rpg.level")
+	deletePath(doc, "tidak.ada")
+	if _, ok := getPath(doc, "rpg.level"); ok {
+		t.Error("deletePath rpg.level gagal")
+	}
+
+	for path, want := range map[string]bool{"money": true, "rpg.exp": true, "": false, "rpg.": false, ".money": false} {
+		if got := validPath(path); got != want {
+			t.Errorf("validPath(%q) = %v, mau %v", path, got, want)
+		}
+	}
+}
+
+func TestApplyOps(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		ops     []UserOp
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"inc default 1", map[string]interface{}{"money": 5.0},
+			[]UserOp{{Op: "inc", Path: "money"}}, map[string]interface{}{"money": 6.0}, false},
+		{"inc field kosong dari 0", map[string]interface{}{},
+			[]UserOp{{Op: "inc", Path: "rpg.exp", Value: 3.0}},
+			map[string]interface{}{"rpg": map[string]interface{}{"exp": 3.0}}, false},
+		{"dec", map[string]interface{}{"money": 5.0},
+			[]UserOp{{Op: "dec", Path: "money", Value: 2.0}}, map[string]interface{}{"money": 3.0}, false},
+		{"min dan max", map[string]interface{}{"a": 5.0, "b": 5.0},
+			[]UserOp{{Op: "min", Path: "a", Value: 7.0}, {Op: "max", Path: "b", Value: 7.0}},
+			map[string]interface{}{"a": 5.0, "b": 7.0}, false},
+		{"set dan unset", map[string]interface{}{"a": 1.0, "b": 1.0},
+			[]UserOp{{Op: "set", Path: "a", Value: "x"}, {Op: "unset", Path: "b"}},
+			map[string]interface{}{"a": "x"}, false},
+		{"inc nilai bukan angka", map[string]interface{}{"a": "x"},
+			[]UserOp{{Op: "inc", Path: "a"}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyOps(tt.doc, tt.ops)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.doc, tt.want) {
+				t.Errorf("doc = %v, mau %v", tt.doc, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOps(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []UserOp
+		ok   bool
+	}{
+		{"kosong", nil, false},
+		{"path tidak valid", []UserOp{{Op: "inc", Path: "a..b"}}, false},
+		{"operasi tidak dikenal", []UserOp{{Op: "mul", Path: "money"}}, false},
+		{"inc value string", []UserOp{{Op: "inc", Path: "money", Value: "1"}}, false},
+		{"min tanpa value", []UserOp{{Op: "min", Path: "money"}}, false},
+		{"valid", []UserOp{{Op: "inc", Path: "money"}, {Op: "set", Path: "name", Value: "x"}, {Op: "unset", Path: "a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOps(tt.ops)
+			var opErr *OpError
+			if tt.ok != (err == nil) || (err != nil && !errors.As(err, &opErr)) {
+				t.Errorf("validateOps = %v", err)
+			}
+		})
+	}
+}
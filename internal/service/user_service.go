@@ -6,249 +6,263 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
-	"time"
+	"reflect"
+	"sync"
 )
 
-func getFloat(m map[string]interface{}, key string) float64 {
-	if val, ok := m[key]; ok {
-		if f, ok := val.(float64); ok {
-			return f
-		}
+// ErrUserNotFound dikembalikan kalau user belum pernah tersimpan
+var ErrUserNotFound = repository.ErrUserNotFound
+
+// ErrInvalidDocument dikembalikan kalau dokumen user tidak bisa di-decode
+// (pelanggaran schema dikembalikan sebagai *ValidationError)
+var ErrInvalidDocument = errors.New("dokumen user tidak valid")
+
+// batas percobaan ulang read-modify-write saat versi bentrok
+const maxConflictRetries = 5
+
+type UserService struct {
+	Repo repository.UserStore
+	// StrictSchema menolak key yang tidak ada di schema user (USER_SCHEMA_MODE=strict)
+	StrictSchema bool
+	// Claims adalah tabel klaim hadiah per periode (CLAIM_CONFIG, default DefaultClaims)
+	Claims map[string]ClaimPeriod
+
+	backfillMu      sync.Mutex
+	backfillRunning bool
+}
+
+func NewUserService(repo repository.UserStore) *UserService {
+	return &UserService{Repo: repo, Claims: DefaultClaims}
+}
+
+// withDefaultReason memberi alasan penulisan untuk ledger kalau pemanggil
+// (handler) belum memberikannya
+func withDefaultReason(ctx context.Context, reason string) context.Context {
+	if repository.ReasonFrom(ctx) != "" {
+		return ctx
 	}
-	return 0
+	return repository.WithReason(ctx, reason)
 }
 
-type UserService struct {
-	Repo *repository.UserRepository
+// retryOnConflict mengulang read-modify-write kalau ada penulis lain yang
+// lebih dulu menyimpan dokumen yang sama
+func retryOnConflict(fn func() error) error {
+	var err error
+	for i := 0; i < maxConflictRetries; i++ {
+		err = fn()
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{Repo: repo}
+// decodeUserMap memvalidasi dokumen map (dari body request atau hasil
+// patch/ops) lalu mengubahnya menjadi entity.User. Key default yang tidak ada
+// diisi nilai default. before adalah dokumen tersimpan sebelum diubah (nil
+// untuk dokumen baru), lihat validateDocument.
+func (s *UserService) decodeUserMap(m, before map[string]interface{}) (*entity.User, error) {
+	if err := s.validateDocument(m, before); err != nil {
+		return nil, err
+	}
+	user, err := entity.UserFromMap(m)
+	var mismatch *entity.TypeMismatchError
+	if errors.As(err, &mismatch) && before != nil {
+		// Nilai lama yang tipenya salah boleh tetap ada (disimpan apa adanya),
+		// asal tidak ikut diubah
+		for _, path := range mismatch.Fields {
+			old, existed := getPath(before, path)
+			current, _ := getPath(m, path)
+			if !existed || !reflect.DeepEqual(old, current) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+			}
+		}
+		return user, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return user, nil
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, userID string, body map[string]interface{}) error {
-	// cek user apakah ada
-	_, err := s.Repo.GetUser(ctx, userID)
+// mutateAsMap menjalankan fn terhadap bentuk map dari user (untuk operasi
+// berbasis dotted path), lalu mengubah hasilnya kembali ke user
+func (s *UserService) mutateAsMap(user *entity.User, fn func(doc map[string]interface{}) error) error {
+	doc := user.ToMap()
+	if err := fn(doc); err != nil {
+		return err
+	}
+	updated, err := s.decodeUserMap(doc, user.ToMap())
 	if err != nil {
 		return err
 	}
+	*user = *updated
+	return nil
+}
+
+// UpdateUser menimpa seluruh dokumen user. expectedVersion diisi dari
+// header If-Match (repository.AnyVersion kalau tidak ada). Field default yang
+// tidak ada di body diisi nilai default. Kalau ada guardExprs, user harus
+// sudah ada dan guard dicek terhadap dokumen lama di dalam MutateUser yang
+// sama dengan penulisannya.
+func (s *UserService) UpdateUser(ctx context.Context, userID string, body map[string]interface{}, expectedVersion int64, guardExprs []string) (int64, error) {
+	guards, err := ParseGuards(guardExprs)
+	if err != nil {
+		return 0, err
+	}
+	ctx = withDefaultReason(ctx, "update")
+	user, err := s.decodeUserMap(body, nil)
+	if err != nil {
+		return 0, err
+	}
+	user.ID = userID
+	ctx = repository.WithExpectedVersion(ctx, expectedVersion)
+	if len(guards) == 0 {
+		// Simpan data baru (menimpa data lama)
+		return s.Repo.SaveUser(ctx, userID, user, expectedVersion)
+	}
 
-	// Simpan data baru (menimpa data lama)
-	return s.Repo.SaveUser(ctx, userID, body)
+	ctx = withGuardRecheck(ctx, guards)
+	_, version, err := s.Repo.MutateUser(ctx, userID, func(stored *entity.User) error {
+		if err := checkGuards(stored.ToMap(), guards); err != nil {
+			return err
+		}
+		*stored = *user
+		return nil
+	})
+	return version, err
+}
+
+// PatchUser menggabungkan patch (RFC 7396) ke dokumen yang tersimpan,
+// lalu menyimpan hasilnya dalam satu MutateUser. Kolom index (username,
+// money, level) ikut dihitung ulang dari dokumen hasil merge. Field default
+// yang dihapus (null) kembali ke nilai default. guardExprs dicek terhadap
+// dokumen sebelum patch; expectedVersion dari header If-Match.
+func (s *UserService) PatchUser(ctx context.Context, userID string, patch map[string]interface{}, expectedVersion int64, guardExprs []string) (*entity.User, int64, error) {
+	guards, err := ParseGuards(guardExprs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx = withDefaultReason(ctx, "patch")
+	ctx = repository.WithExpectedVersion(ctx, expectedVersion)
+	ctx = withGuardRecheck(ctx, guards)
+	return s.Repo.MutateUser(ctx, userID, func(user *entity.User) error {
+		return s.mutateAsMap(user, func(doc map[string]interface{}) error {
+			if err := checkGuards(doc, guards); err != nil {
+				return err
+			}
+			ApplyMergePatch(doc, patch)
+			return nil
+		})
+	})
 }
 
 // GetOrInitUser: Logic inti sinkronisasi data
-func (s *UserService) GetOrInitUser(ctx context.Context, userID string, usernameQuery string) (map[string]interface{}, error) {
-	userMap, err := s.Repo.GetUser(ctx, userID)
+// Mengembalikan user beserta versi dokumennya.
+func (s *UserService) GetOrInitUser(ctx context.Context, userID string, usernameQuery string) (*entity.User, int64, error) {
+	ctx = withDefaultReason(ctx, "init")
+	var user *entity.User
+	var version int64
+
+	err := retryOnConflict(func() error {
+		var err error
+		user, version, _, err = s.getOrInitUser(ctx, userID, usernameQuery)
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	return user, version, nil
+}
+
+// getOrInitUser juga mengembalikan apakah dokumen ikut disimpan
+func (s *UserService) getOrInitUser(ctx context.Context, userID string, usernameQuery string) (*entity.User, int64, bool, error) {
+	user, version, err := s.Repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, 0, false, err
 	}
 
 	needsSave := false
-	defaults := entity.GetDefaultUserMap()
 
 	// Jika user baru (belum ada di DB)
-	if userMap == nil {
-		userMap = defaults
-		userMap["username"] = "New User"
+	if user == nil {
+		user = entity.NewUser()
+		user.Username = "New User"
 		if usernameQuery != "" {
-			userMap["username"] = usernameQuery
+			user.Username = usernameQuery
 		}
-		// Set nested objects default
-		userMap["rpg"] = entity.GetDefaultRPG()
-		userMap["jail"] = entity.JailStats{Status: false}
 
 		needsSave = true
 	} else {
-		// Logic Sync: Cek properti yang hilang
-		// Loop default keys
-		for k, v := range defaults {
-			if _, exists := userMap[k]; !exists {
-				userMap[k] = v
-				needsSave = true
-			}
-		}
-
-		// Sync RPG Object
-		rpgDefault := entity.GetDefaultRPG()
-		// Convert struct to map for comparison logic (simplified)
-		if _, ok := userMap["rpg"]; !ok {
-			userMap["rpg"] = rpgDefault
+		// Logic Sync: field default yang hilang (termasuk nested rpg, jail,
+		// motor) sudah terisi default oleh repository, tinggal disimpan
+		if len(user.MissingFields()) > 0 {
 			needsSave = true
-		} else {
-			// Deep check RPG properties
-			rpgMap := userMap["rpg"].(map[string]interface{})
-			// Cek field penting RPG, misal level, exp
-			if _, ok := rpgMap["level"]; !ok {
-				rpgMap["level"] = 1
-				needsSave = true
-			}
-			if _, ok := rpgMap["health"]; !ok {
-				rpgMap["health"] = 100
-				needsSave = true
-			}
 		}
 
 		// Update username jika ada di query
-		currName, _ := userMap["username"].(string)
-		if usernameQuery != "" && currName != usernameQuery {
-			userMap["username"] = usernameQuery
+		if usernameQuery != "" && user.Username != usernameQuery {
+			user.Username = usernameQuery
 			needsSave = true
 		}
 	}
 
 	//Save jika ada perubahan
 	if needsSave {
-		// Pastikan ID tersimpan di dalam map juga (opsional, tp bagus buat konsistensi)
-		userMap["id"] = userID
-		err = s.Repo.SaveUser(ctx, userID, userMap)
+		// Pastikan ID tersimpan di dalam dokumen juga
+		user.ID = userID
+		version, err = s.Repo.SaveUser(ctx, userID, user, version)
 		if err != nil {
-			return nil, err
+			return nil, 0, false, err
 		}
 	}
 
-	return userMap, nil
+	return user, version, needsSave, nil
 }
 
+// ClaimDaily adalah klaim periode "daily" (lihat Claim) dengan bentuk
+// response lama POST /daily/:userId
 func (s *UserService) ClaimDaily(ctx context.Context, userID string) (map[string]interface{}, error) {
-	//Ambil User
-	user, err := s.Repo.GetUser(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-	if user == nil {
-		return nil, errors.New("user not found")
-	}
-
-	//  Cek Cooldown (86400000 ms = 24 jam)
-	lastDaily := getFloat(user, "lastDaily")
-	now := time.Now().UnixMilli()
-
-	cooldown := int64(86400000)
-	timePassed := now - int64(lastDaily)
-
-	if timePassed < cooldown {
-		// Hitung sisa waktu
-		sisa := cooldown - timePassed
-		hours := sisa / 3600000
-		minutes := (sisa % 3600000) / 60000
-		return nil, fmt.Errorf("cooldown! tunggu %d jam %d menit lagi", hours, minutes)
-	}
-
-	// Berikan Hadiah
-	rewardMoney := 10000.0
-	rewardExp := 200.0
-	rewardDiamond := 1.0
-
-	// Update Money
-	currentMoney := getFloat(user, "money")
-	user["money"] = currentMoney + rewardMoney
-	currentDiamond := getFloat(user, "diamond")
-	user["diamond"] = currentDiamond + rewardDiamond
-
-	// Update Exp (Nested Logic)
-	if rpg, ok := user["rpg"].(map[string]interface{}); ok {
-		currentExp := getFloat(rpg, "exp")
-		rpg["exp"] = currentExp + rewardExp
-		user["rpg"] = rpg
-	}
-
-	// Update Waktu
-	user["lastDaily"] = float64(now) // Simpan sebagai float biar konsisten JSON
-
-	// Simpan
-	err = s.Repo.SaveUser(ctx, userID, user)
+	result, err := s.Claim(ctx, userID, "daily")
 	if err != nil {
 		return nil, err
 	}
 
 	// Return data user terbaru atau pesan sukses
 	return map[string]interface{}{
-		"status":    true,
-		"message":   fmt.Sprintf("Berhasil klaim harian! Dapat Rp %.0f dan %.0f Diamond.", rewardMoney, rewardDiamond),
-		"money":     user["money"],
-		"diamond":   user["diamond"],
-		"lastDaily": user["lastDaily"],
+		"status":      true,
+		"message":     result.Message(),
+		"money":       result.User.Money,
+		"diamond":     result.User.Diamond,
+		"lastDaily":   result.User.LastDaily,
+		"streak":      result.User.DailyStreak,
+		"nextClaimAt": result.NextClaimAt,
 	}, nil
 }
 
-// Logic Leaderboard
-func (s *UserService) GetLeaderboard(ctx context.Context, lbType string, limit int) ([]map[string]interface{}, int, error) {
-	usersMap, err := s.Repo.GetAllUsers(ctx)
-	if err != nil {
-		return nil, 0, err
-	}
+func (s *UserService) GetAFKUsers(ctx context.Context) (map[string]*entity.User, error) {
+	return s.Repo.GetAFKUsers(ctx)
+}
 
-	var users []map[string]interface{}
-	for _, u := range usersMap {
-		users = append(users, u)
+// GetHistory mengambil riwayat ledger user dengan cursor pagination
+func (s *UserService) GetHistory(ctx context.Context, userID, field string, limit int, cursor int64) ([]repository.LedgerEntry, int64, error) {
+	if limit <= 0 {
+		limit = 20
 	}
-
-	totalUsers := len(users) // total data user nya
-
-	sort.Slice(users, func(i, j int) bool {
-		a := users[i]
-		b := users[j]
-		getVal := func(m map[string]interface{}, key string) float64 {
-			if v, ok := m[key].(float64); ok {
-				return v
-			}
-			return 0
-		}
-
-		getNested := func(m map[string]interface{}, p1, p2 string) float64 {
-			if parent, ok := m[p1].(map[string]interface{}); ok {
-				if v, ok := parent[p2].(float64); ok {
-					return v
-				}
-			}
-			return 0
-		}
-
-		switch lbType {
-		case "money":
-			return getVal(a, "money") > getVal(b, "money")
-		case "level":
-			return getNested(a, "rpg", "level") > getNested(b, "rpg", "level")
-		case "wealth":
-			wa := getVal(a, "money") + getVal(a, "diamond")
-			wb := getVal(b, "money") + getVal(b, "diamond")
-			return wa > wb
-		}
-		return false
-	})
-	if limit > len(users) {
-		limit = len(users)
-	}
-	topUsers := users[:limit]
-
-	var result []map[string]interface{}
-	for _, u := range topUsers {
-		res := map[string]interface{}{
-			"userId":   u["id"],
-			"username": u["username"],
-			"money":    u["money"],
-			"diamond":  u["diamond"],
-		}
-
-		if rpg, ok := u["rpg"].(map[string]interface{}); ok {
-			res["level"] = rpg["level"]
-		} else {
-			res["level"] = 0
-		}
-
-		if lbType == "wealth" {
-			money, _ := u["money"].(float64)
-			diamond, _ := u["diamond"].(float64)
-			res["wealth"] = money + diamond
-		}
-		result = append(result, res)
+	if limit > 100 {
+		limit = 100
 	}
-
-	// Kembalikan: (DataTop10, AngkaTotalUser, Error)
-	return result, totalUsers, nil
+	return s.Repo.GetHistory(ctx, userID, field, limit, cursor)
 }
-func (s *UserService) GetAFKUsers(ctx context.Context) (map[string]interface{}, error) {
-	return s.Repo.GetAFKUsers(ctx)
+
+// GetAuditLog mengambil catatan perubahan dokumen untuk moderator
+func (s *UserService) GetAuditLog(ctx context.Context, userID string, since int64, limit int) ([]repository.AuditEntry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	return s.Repo.GetAuditLog(ctx, userID, since, limit)
 }
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"context"
+	"testing"
+)
+
+// Field tersimpan yang tipenya salah tidak boleh direset oleh operasi lain,
+// tapi tidak boleh diubah menjadi nilai salah tipe yang baru
+func TestMistypedFieldPreserved(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, "u1", func(u *entity.User) {
+		u.Extra = map[string]interface{}{"money": "banyak"}
+	})
+
+	user, _, err := s.ApplyOps(ctx, "u1", []UserOp{{Op: "inc", Path: "diamond"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := user.ToMap(); doc["money"] != "banyak" || doc["diamond"] != 1.0 {
+		t.Errorf("money = %v diamond = %v", doc["money"], doc["diamond"])
+	}
+	if _, _, err := s.ApplyOps(ctx, "u1", []UserOp{{Op: "set", Path: "bank", Value: "x"}}, nil); err == nil {
+		t.Error("set bank ke string harusnya ditolak")
+	}
+	user, _, err = s.ApplyOps(ctx, "u1", []UserOp{{Op: "set", Path: "money", Value: 10.0}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := user.ToMap(); doc["money"] != 10.0 {
+		t.Errorf("money tidak diperbaiki: %v", doc["money"])
+	}
+}
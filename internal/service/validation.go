@@ -0,0 +1,47 @@
+package service
+
+import (
+	"Berpg/internal/entity"
+	"fmt"
+	"reflect"
+)
+
+// ValidationError berisi semua pelanggaran schema pada dokumen user yang
+// akan ditulis. UserID hanya diisi untuk transaksi multi-user.
+type ValidationError struct {
+	UserID string
+	Errors []entity.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("dokumen user tidak sesuai schema (%d field)", len(e.Errors))
+	if e.UserID != "" {
+		msg = "user " + e.UserID + ": " + msg
+	}
+	return msg
+}
+
+// validateDocument memeriksa doc terhadap schema user. Kalau before diisi
+// (perubahan atas dokumen tersimpan), pelanggaran pada nilai yang tidak ikut
+// berubah diabaikan, supaya data lama yang belum rapi tidak memblokir semua
+// penulisan ke user tersebut.
+func (s *UserService) validateDocument(doc, before map[string]interface{}) error {
+	errs := entity.ValidateUserMap(doc, s.StrictSchema)
+	if before != nil {
+		changed := errs[:0]
+		for _, e := range errs {
+			old, existed := getPath(before, e.Path)
+			current, _ := getPath(doc, e.Path)
+			if existed && reflect.DeepEqual(old, current) {
+				continue
+			}
+			changed = append(changed, e)
+		}
+		errs = changed
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}